@@ -0,0 +1,216 @@
+package cliconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/afero"
+)
+
+const (
+	configFileEnv       = "TF_CLI_CONFIG_FILE"
+	tokenEnvPrefix      = "TF_TOKEN_"
+	configFileName      = ".terraformrc"
+	configDirName       = ".terraform.d"
+	credentialsFileName = "credentials.tfrc.json"
+)
+
+const (
+	InstallationMethodDirect           = "direct"
+	InstallationMethodNetworkMirror    = "network_mirror"
+	InstallationMethodFilesystemMirror = "filesystem_mirror"
+)
+
+// Config contains the parts of the Terraform CLI configuration used when looking up versions.
+type Config struct {
+	// ProviderInstallation contains the provider installation methods in the order they are configured.
+	ProviderInstallation []*InstallationMethod
+	credentials          map[string]string
+}
+
+type InstallationMethod struct {
+	Type    string
+	URL     string
+	Path    string
+	Include []string
+	Exclude []string
+}
+
+type configFile struct {
+	Credentials          []*credentialsBlock          `hcl:"credentials,block"`
+	ProviderInstallation []*providerInstallationBlock `hcl:"provider_installation,block"`
+	Remain               hcl.Body                     `hcl:",remain"`
+}
+
+type providerInstallationBlock struct {
+	Body hcl.Body `hcl:",remain"`
+}
+
+type installationMethodBlock struct {
+	URL     string   `hcl:"url,optional"`
+	Path    string   `hcl:"path,optional"`
+	Include []string `hcl:"include,optional"`
+	Exclude []string `hcl:"exclude,optional"`
+	Remain  hcl.Body `hcl:",remain"`
+}
+
+type credentialsBlock struct {
+	Host   string   `hcl:"host,label"`
+	Token  string   `hcl:"token,optional"`
+	Remain hcl.Body `hcl:",remain"`
+}
+
+type credentialsFile struct {
+	Credentials map[string]struct {
+		Token string `json:"token"`
+	} `json:"credentials"`
+}
+
+func NewConfig() *Config {
+	return &Config{
+		credentials: map[string]string{},
+	}
+}
+
+// Load reads the CLI configuration file from TF_CLI_CONFIG_FILE or the home directory together with credentials.tfrc.json.
+// Missing files are not an error, and the files in the home directory are skipped when there is no home directory.
+func Load(fs afero.Fs) (*Config, error) {
+	// containers often run without a home directory, in which case only TF_CLI_CONFIG_FILE and TF_TOKEN_* are used
+	home, _ := os.UserHomeDir()
+	configPath := os.Getenv(configFileEnv)
+	if configPath == "" && home != "" {
+		configPath = filepath.Join(home, configFileName)
+	}
+
+	cfg := NewConfig()
+	if home != "" {
+		err := cfg.loadCredentialsFile(fs, filepath.Join(home, configDirName, credentialsFileName))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if configPath != "" {
+		err := cfg.loadConfigFile(fs, configPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadConfigFile(fs afero.Fs, path string) error {
+	b, err := afero.ReadFile(fs, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if filepath.Ext(path) == ".json" {
+		file, diags = parser.ParseJSON(b, path)
+	} else {
+		file, diags = parser.ParseHCL(b, path)
+	}
+	if diags.HasErrors() {
+		return fmt.Errorf("unable to parse cli config %s: %w", path, diags)
+	}
+	cf := configFile{}
+	diags = gohcl.DecodeBody(file.Body, nil, &cf)
+	if diags.HasErrors() {
+		return fmt.Errorf("unable to decode cli config %s: %w", path, diags)
+	}
+
+	for _, cred := range cf.Credentials {
+		c.credentials[strings.ToLower(cred.Host)] = cred.Token
+	}
+	for _, pi := range cf.ProviderInstallation {
+		methods, err := parseProviderInstallation(pi)
+		if err != nil {
+			return fmt.Errorf("unable to decode provider installation in cli config %s: %w", path, err)
+		}
+		c.ProviderInstallation = append(c.ProviderInstallation, methods...)
+	}
+	return nil
+}
+
+func parseProviderInstallation(pi *providerInstallationBlock) ([]*InstallationMethod, error) {
+	schema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: InstallationMethodDirect},
+			{Type: InstallationMethodNetworkMirror},
+			{Type: InstallationMethodFilesystemMirror},
+		},
+	}
+	// blocks have to be read in order as the first matching method is used
+	content, _, diags := pi.Body.PartialContent(schema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	methods := []*InstallationMethod{}
+	for _, block := range content.Blocks {
+		mb := installationMethodBlock{}
+		diags := gohcl.DecodeBody(block.Body, nil, &mb)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if block.Type == InstallationMethodNetworkMirror && mb.URL == "" {
+			return nil, errors.New("network_mirror url cannot be empty")
+		}
+		if block.Type == InstallationMethodFilesystemMirror && mb.Path == "" {
+			return nil, errors.New("filesystem_mirror path cannot be empty")
+		}
+		methods = append(methods, &InstallationMethod{
+			Type:    block.Type,
+			URL:     mb.URL,
+			Path:    mb.Path,
+			Include: mb.Include,
+			Exclude: mb.Exclude,
+		})
+	}
+	return methods, nil
+}
+
+func (c *Config) loadCredentialsFile(fs afero.Fs, path string) error {
+	b, err := afero.ReadFile(fs, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cf := credentialsFile{}
+	err = json.Unmarshal(b, &cf)
+	if err != nil {
+		return fmt.Errorf("unable to parse credentials file %s: %w", path, err)
+	}
+	for host, cred := range cf.Credentials {
+		c.credentials[strings.ToLower(host)] = cred.Token
+	}
+	return nil
+}
+
+// Token returns the API token for the host, a TF_TOKEN_ environment variable takes precedence over the configuration files.
+func (c *Config) Token(host string) string {
+	host = strings.ToLower(host)
+	envName := tokenEnvPrefix + strings.ReplaceAll(strings.ReplaceAll(host, ".", "_"), "-", "__")
+	if token := os.Getenv(envName); token != "" {
+		return token
+	}
+	if c == nil {
+		return ""
+	}
+	return c.credentials[host]
+}
@@ -0,0 +1,121 @@
+package cliconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	fs := afero.NewMemMapFs()
+	err = afero.WriteFile(fs, "/tmp/terraformrc", []byte(configFileContent), os.FileMode(0600))
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, filepath.Join(home, configDirName, credentialsFileName), []byte(credentialsFileContent), os.FileMode(0600))
+	require.NoError(t, err)
+	t.Setenv(configFileEnv, "/tmp/terraformrc")
+	t.Setenv("TF_TOKEN_tf_corp__internal_example", "env-token")
+
+	cfg, err := Load(fs)
+	require.NoError(t, err)
+	require.Equal(t, "config-token", cfg.Token("app.terraform.io"))
+	require.Equal(t, "json-token", cfg.Token("Registry.Example.com"))
+	require.Equal(t, "env-token", cfg.Token("tf.corp-internal.example"))
+	require.Empty(t, cfg.Token("registry.terraform.io"))
+
+	require.Equal(t, []*InstallationMethod{
+		{
+			Type:    InstallationMethodNetworkMirror,
+			URL:     "https://mirror.example.com/providers/",
+			Include: []string{"registry.terraform.io/hashicorp/*"},
+		},
+		{
+			Type:    InstallationMethodFilesystemMirror,
+			Path:    "/usr/share/terraform/providers",
+			Include: []string{"tf.corp.example/*/*"},
+		},
+		{
+			Type:    InstallationMethodDirect,
+			Exclude: []string{"registry.terraform.io/hashicorp/*"},
+		},
+	}, cfg.ProviderInstallation)
+}
+
+func TestLoadMissing(t *testing.T) {
+	t.Setenv(configFileEnv, "/tmp/terraformrc")
+
+	cfg, err := Load(afero.NewMemMapFs())
+	require.NoError(t, err)
+	require.Empty(t, cfg.Token("app.terraform.io"))
+}
+
+func TestLoadWithoutHome(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "/tmp/terraformrc", []byte(configFileContent), os.FileMode(0600))
+	require.NoError(t, err)
+	t.Setenv("HOME", "")
+	t.Setenv(configFileEnv, "/tmp/terraformrc")
+	t.Setenv("TF_TOKEN_tf_corp__internal_example", "env-token")
+
+	cfg, err := Load(fs)
+	require.NoError(t, err)
+	require.Equal(t, "config-token", cfg.Token("app.terraform.io"))
+	require.Equal(t, "env-token", cfg.Token("tf.corp-internal.example"))
+
+	t.Setenv(configFileEnv, "")
+	cfg, err = Load(fs)
+	require.NoError(t, err)
+	require.Equal(t, "env-token", cfg.Token("tf.corp-internal.example"))
+}
+
+func TestLoadNetworkMirrorWithoutURL(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "/tmp/terraformrc", []byte("provider_installation {\n  network_mirror {}\n}\n"), os.FileMode(0600))
+	require.NoError(t, err)
+	t.Setenv(configFileEnv, "/tmp/terraformrc")
+
+	_, err = Load(fs)
+	require.Error(t, err)
+}
+
+func TestTokenEnvPrecedence(t *testing.T) {
+	t.Setenv("TF_TOKEN_app_terraform_io", "env-token")
+
+	cfg := NewConfig()
+	cfg.credentials["app.terraform.io"] = "config-token"
+	require.Equal(t, "env-token", cfg.Token("app.terraform.io"))
+}
+
+const configFileContent = `
+plugin_cache_dir = "$HOME/.terraform.d/plugin-cache"
+
+credentials "app.terraform.io" {
+  token = "config-token"
+}
+
+provider_installation {
+  network_mirror {
+    url     = "https://mirror.example.com/providers/"
+    include = ["registry.terraform.io/hashicorp/*"]
+  }
+  filesystem_mirror {
+    path    = "/usr/share/terraform/providers"
+    include = ["tf.corp.example/*/*"]
+  }
+  direct {
+    exclude = ["registry.terraform.io/hashicorp/*"]
+  }
+}
+`
+
+const credentialsFileContent = `{
+  "credentials": {
+    "registry.example.com": {
+      "token": "json-token"
+    }
+  }
+}`
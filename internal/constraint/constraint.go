@@ -0,0 +1,219 @@
+package constraint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var termRegex = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*v?(\d+(?:\.\d+){0,2})(-[0-9A-Za-z.-]+)?$`)
+
+// term is a single comma separated term of a Terraform version constraint, the
+// number of segments is kept so that the precision is preserved when the version is changed.
+type term struct {
+	operator   string
+	segments   []uint64
+	prerelease string
+}
+
+// Constraints is a parsed Terraform version constraint.
+type Constraints []*term
+
+// Parse parses a Terraform version constraint such as "~> 2.53" or ">= 2.0, < 3.0".
+func Parse(s string) (Constraints, error) {
+	cc := Constraints{}
+	for _, part := range strings.Split(s, ",") {
+		match := termRegex.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil, fmt.Errorf("invalid version constraint %q", s)
+		}
+		segments := []uint64{}
+		for _, seg := range strings.Split(match[2], ".") {
+			n, err := strconv.ParseUint(seg, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			segments = append(segments, n)
+		}
+		cc = append(cc, &term{
+			operator:   match[1],
+			segments:   segments,
+			prerelease: strings.TrimPrefix(match[3], "-"),
+		})
+	}
+	return cc, nil
+}
+
+func (c *term) version() *semver.Version {
+	s := padSegments(c.segments, 3)
+	version := fmt.Sprintf("%d.%d.%d", s[0], s[1], s[2])
+	if c.prerelease != "" {
+		version = fmt.Sprintf("%s-%s", version, c.prerelease)
+	}
+	// the segments and prerelease are already validated when parsing
+	return semver.MustParse(version)
+}
+
+func (c *term) check(v *semver.Version) bool {
+	cv := c.version()
+	switch c.operator {
+	case "", "=":
+		return v.Equal(cv)
+	case "!=":
+		return !v.Equal(cv)
+	case ">":
+		return v.GreaterThan(cv)
+	case ">=":
+		return !v.LessThan(cv)
+	case "<":
+		return v.LessThan(cv)
+	case "<=":
+		return !v.GreaterThan(cv)
+	case "~>":
+		// only the right-most segment of the constraint is allowed to increase
+		prefix := padSegments(c.segments[:len(c.segments)-1], 0)
+		actual := []uint64{v.Major(), v.Minor(), v.Patch()}
+		for i, seg := range prefix {
+			if actual[i] != seg {
+				return false
+			}
+		}
+		return !v.LessThan(cv)
+	}
+	return false
+}
+
+func (c *term) String() string {
+	parts := []string{}
+	for _, seg := range c.segments {
+		parts = append(parts, strconv.FormatUint(seg, 10))
+	}
+	version := strings.Join(parts, ".")
+	if c.prerelease != "" {
+		version = fmt.Sprintf("%s-%s", version, c.prerelease)
+	}
+	if c.operator == "" {
+		return version
+	}
+	return fmt.Sprintf("%s %s", c.operator, version)
+}
+
+// Check returns true if the version satisfies every term.
+func (cc Constraints) Check(v *semver.Version) bool {
+	for _, c := range cc {
+		if !c.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cc Constraints) String() string {
+	parts := []string{}
+	for _, c := range cc {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Excludes returns true if a != term rejects the version.
+func (cc Constraints) Excludes(v *semver.Version) bool {
+	for _, c := range cc {
+		if c.operator == "!=" && !c.check(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Base returns the version the constraint is anchored to, which is the first exact, pessimistic or lower bound term.
+func (cc Constraints) Base() *semver.Version {
+	for _, c := range cc {
+		switch c.operator {
+		case "", "=", "~>", ">", ">=":
+			return c.version()
+		}
+	}
+	return nil
+}
+
+// Bump returns new constraints that allow the latest version while keeping the operators and precision.
+// A range made of a lower and upper bound is shifted so that it starts at the latest version and keeps its width.
+func (cc Constraints) Bump(latest *semver.Version) Constraints {
+	actual := []uint64{latest.Major(), latest.Minor(), latest.Patch()}
+	lower, upper := cc.bounds()
+	bumped := Constraints{}
+	for i, c := range cc {
+		b := &term{operator: c.operator, segments: c.segments, prerelease: c.prerelease}
+		switch {
+		case c.operator == "" || c.operator == "=" || c.operator == "~>":
+			b.segments = truncateSegments(actual, len(c.segments))
+			b.prerelease = ""
+		case lower >= 0 && upper >= 0 && (i == lower || i == upper):
+			b.segments, b.prerelease = shiftRange(cc[lower], cc[upper], c, actual), ""
+		case i == upper && !c.check(latest):
+			// without a lower bound the upper bound is moved just past the latest version
+			b.segments = truncateSegments(actual, len(c.segments))
+			b.segments[len(b.segments)-1]++
+			b.prerelease = ""
+		}
+		bumped = append(bumped, b)
+	}
+	return bumped
+}
+
+// bounds returns the index of the first lower and upper bound terms, or -1 if there is none.
+func (cc Constraints) bounds() (lower, upper int) {
+	lower, upper = -1, -1
+	for i, c := range cc {
+		switch c.operator {
+		case ">", ">=":
+			if lower < 0 {
+				lower = i
+			}
+		case "<", "<=":
+			if upper < 0 {
+				upper = i
+			}
+		}
+	}
+	return lower, upper
+}
+
+// shiftRange moves the term of a range to the latest version at the first segment where the bounds differ.
+func shiftRange(lower, upper, t *term, actual []uint64) []uint64 {
+	l := padSegments(lower.segments, 3)
+	u := padSegments(upper.segments, 3)
+	idx := 0
+	for idx < 2 && l[idx] == u[idx] {
+		idx++
+	}
+	width := uint64(1)
+	if u[idx] > l[idx] {
+		width = u[idx] - l[idx]
+	}
+
+	start := append([]uint64{}, actual...)
+	for i := idx + 1; i < len(start); i++ {
+		start[i] = 0
+	}
+	if t == upper {
+		start[idx] += width
+	}
+	return truncateSegments(start, len(t.segments))
+}
+
+func padSegments(segments []uint64, n int) []uint64 {
+	padded := append([]uint64{}, segments...)
+	for len(padded) < n {
+		padded = append(padded, 0)
+	}
+	return padded
+}
+
+func truncateSegments(segments []uint64, n int) []uint64 {
+	return append([]uint64{}, segments[:n]...)
+}
@@ -0,0 +1,71 @@
+package constraint
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{constraint: "2.53.0", version: "2.53.0", expected: true},
+		{constraint: "= 2.53.0", version: "2.53.1", expected: false},
+		{constraint: "!= 2.53.0", version: "2.53.1", expected: true},
+		{constraint: "~> 2.53", version: "2.99.0", expected: true},
+		{constraint: "~> 2.53", version: "3.4.0", expected: false},
+		{constraint: "~> 2.53.0", version: "2.54.0", expected: false},
+		{constraint: ">= 2.0, < 3.0", version: "2.9.1", expected: true},
+		{constraint: ">= 2.0, < 3.0", version: "3.0.0", expected: false},
+		{constraint: "> 2.0", version: "2.0.0", expected: false},
+		{constraint: "<= 3", version: "3.0.0", expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+"/"+tt.version, func(t *testing.T) {
+			cc, err := Parse(tt.constraint)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cc.Check(semver.MustParse(tt.version)))
+		})
+	}
+}
+
+func TestConstraintParseInvalid(t *testing.T) {
+	_, err := Parse("latest")
+	require.Error(t, err)
+	_, err = Parse(">= 1.0,")
+	require.Error(t, err)
+}
+
+func TestBase(t *testing.T) {
+	tests := []struct {
+		constraint string
+		expected   string
+	}{
+		{constraint: "~> 2.53", expected: "2.53.0"},
+		{constraint: "v1.2.3", expected: "1.2.3"},
+		{constraint: "< 3.0, >= 2.1", expected: "2.1.0"},
+		{constraint: "4.0.0-beta1", expected: "4.0.0-beta1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			cc, err := Parse(tt.constraint)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cc.Base().String())
+		})
+	}
+
+	cc, err := Parse("< 3.0")
+	require.NoError(t, err)
+	require.Nil(t, cc.Base())
+}
+
+func TestExcludes(t *testing.T) {
+	cc, err := Parse(">= 2.0, != 3.4.1")
+	require.NoError(t, err)
+	require.True(t, cc.Excludes(semver.MustParse("3.4.1")))
+	require.False(t, cc.Excludes(semver.MustParse("1.0.0")))
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"errors"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+)
+
+const noNewlineMarker = "\n\\ No newline at end of file\n"
+
+// Diff returns a unified diff for every file below path that has been written to layer and differs from base. The file
+// names are relative to path.
+func Diff(base, layer afero.Fs, path string) (string, error) {
+	exists, err := afero.Exists(layer, path)
+	if err != nil {
+		return "", err
+	}
+	// nothing has been written if the path does not exist in the layer
+	if !exists {
+		return "", nil
+	}
+
+	// file names are relative to the path so the diff does not depend on where it was created
+	root := path
+	info, err := layer.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		root = filepath.Dir(path)
+	}
+
+	var sb strings.Builder
+	err = afero.Walk(layer, path, func(path string, info iofs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		d, err := diffFile(base, layer, path, filepath.ToSlash(name))
+		if err != nil {
+			return err
+		}
+		sb.WriteString(d)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func diffFile(base, layer afero.Fs, path, name string) (string, error) {
+	original, err := afero.ReadFile(base, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	updated, err := afero.ReadFile(layer, path)
+	if err != nil {
+		return "", err
+	}
+
+	ud := difflib.UnifiedDiff{
+		A:        splitLines(string(original)),
+		B:        splitLines(string(updated)),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	}
+	return difflib.GetUnifiedDiffString(ud)
+}
+
+// splitLines splits s into lines keeping the line endings, unlike difflib.SplitLines it does not add an empty last line.
+// A last line without a line ending gets the marker used by diff and git so that the next header starts on a new line.
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += noNewlineMarker
+	return lines
+}
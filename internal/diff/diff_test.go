@@ -0,0 +1,138 @@
+package diff
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	base := afero.NewMemMapFs()
+	err := base.MkdirAll("/tmp/terraform/modules/", os.FileMode(0777))
+	require.NoError(t, err)
+	err = afero.WriteFile(base, "/tmp/terraform/main.tf", []byte(original), os.FileMode(0644))
+	require.NoError(t, err)
+	err = afero.WriteFile(base, "/tmp/terraform/modules/main.tf", []byte(original), os.FileMode(0644))
+	require.NoError(t, err)
+
+	layer := afero.NewMemMapFs()
+	fs := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), layer)
+	err = afero.WriteFile(fs, "/tmp/terraform/main.tf", []byte(updated), os.FileMode(0644))
+	require.NoError(t, err)
+	// unchanged files should not be part of the diff
+	err = afero.WriteFile(fs, "/tmp/terraform/modules/main.tf", []byte(original), os.FileMode(0644))
+	require.NoError(t, err)
+
+	d, err := Diff(base, layer, "/tmp/terraform")
+	require.NoError(t, err)
+	require.Equal(t, expectedDiff, d)
+}
+
+func TestDiffAbsolutePath(t *testing.T) {
+	base := afero.NewMemMapFs()
+	err := afero.WriteFile(base, "/home/ci/repo/infra/main.tf", []byte(original), os.FileMode(0644))
+	require.NoError(t, err)
+
+	layer := afero.NewMemMapFs()
+	fs := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), layer)
+	err = afero.WriteFile(fs, "/home/ci/repo/infra/main.tf", []byte(updated), os.FileMode(0644))
+	require.NoError(t, err)
+
+	d, err := Diff(base, layer, "/home/ci/repo")
+	require.NoError(t, err)
+	require.Contains(t, d, "--- a/infra/main.tf\n+++ b/infra/main.tf\n")
+
+	// a single file is relative to its directory
+	d, err = Diff(base, layer, "/home/ci/repo/infra/main.tf")
+	require.NoError(t, err)
+	require.Equal(t, expectedDiff, d)
+}
+
+func TestDiffNoNewline(t *testing.T) {
+	base := afero.NewMemMapFs()
+	layer := afero.NewMemMapFs()
+	fs := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), layer)
+	for _, name := range []string{"a.tf", "b.tf"} {
+		err := afero.WriteFile(base, filepath.Join("/tmp/terraform", name), []byte("version = \"1\""), os.FileMode(0644))
+		require.NoError(t, err)
+		err = afero.WriteFile(fs, filepath.Join("/tmp/terraform", name), []byte("version = \"2\""), os.FileMode(0644))
+		require.NoError(t, err)
+	}
+
+	d, err := Diff(base, layer, "/tmp/terraform")
+	require.NoError(t, err)
+	require.Equal(t, expectedNoNewlineDiff, d)
+
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"a.tf", "b.tf"} {
+		err = os.WriteFile(filepath.Join(dir, name), []byte("version = \"1\""), os.FileMode(0644))
+		require.NoError(t, err)
+	}
+	cmd := exec.Command(git, "apply", "--check", "-")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(d)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	base := afero.NewMemMapFs()
+	err := afero.WriteFile(base, "/tmp/terraform/main.tf", []byte(original), os.FileMode(0644))
+	require.NoError(t, err)
+
+	d, err := Diff(base, afero.NewMemMapFs(), "/tmp/terraform")
+	require.NoError(t, err)
+	require.Empty(t, d)
+}
+
+const original = `resource "helm_release" "aad_pod_identity" {
+  repository = "https://raw.githubusercontent.com/Azure/aad-pod-identity/master/charts"
+  chart      = "aad-pod-identity"
+  name       = "aad-pod-identity"
+  version    = "2.1.0"
+}
+`
+
+const updated = `resource "helm_release" "aad_pod_identity" {
+  repository = "https://raw.githubusercontent.com/Azure/aad-pod-identity/master/charts"
+  chart      = "aad-pod-identity"
+  name       = "aad-pod-identity"
+  version    = "3.0.3"
+}
+`
+
+const expectedDiff = `--- a/main.tf
++++ b/main.tf
+@@ -2,5 +2,5 @@
+   repository = "https://raw.githubusercontent.com/Azure/aad-pod-identity/master/charts"
+   chart      = "aad-pod-identity"
+   name       = "aad-pod-identity"
+-  version    = "2.1.0"
++  version    = "3.0.3"
+ }
+`
+
+const expectedNoNewlineDiff = `--- a/a.tf
++++ b/a.tf
+@@ -1 +1 @@
+-version = "1"
+\ No newline at end of file
++version = "2"
+\ No newline at end of file
+--- a/b.tf
++++ b/b.tf
+@@ -1 +1 @@
+-version = "1"
+\ No newline at end of file
++version = "2"
+\ No newline at end of file
+`
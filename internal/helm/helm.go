@@ -3,54 +3,76 @@ package helm
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/spf13/afero"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/xenitab/tf-provider-latest/internal/annotation"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
 	"github.com/xenitab/tf-provider-latest/internal/result"
 	"github.com/xenitab/tf-provider-latest/internal/util"
 )
 
-func Update(fs afero.Fs, path string, r Repository, helmSelector *[]string) (*result.Result, error) {
-	hclFile, hclWriteFile, annos, err := util.ReadHCLFile(fs, path)
+// Lookups returns a lookup of the latest version for every helm release in the file which would be updated, keyed by
+// repository and chart so that the same chart in multiple files is only looked up once. Errors are returned when the
+// file is updated.
+func Lookups(fs afero.Fs, path string, r Repository, helmSelector *[]string) (map[string]func(), error) {
+	hclFile, _, annos, err := util.ReadHCLFile(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read helm releases for %s: %w", path, err)
 	}
-	hh, err := parseHelmReleases(hclFile)
+	hh, err := parseHelmReleases(hclFile, filepath.Dir(path))
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse helm releases for %s: %w", path, err)
 	}
 
-	selector := map[string]string{}
-	if helmSelector != nil {
-		for _, s := range *helmSelector {
-			selector[s] = s
+	lookups := map[string]func(){}
+	for _, h := range hh {
+		if h.repository == "" || ignoreReason(h, annos, helmSelector) != "" {
+			continue
+		}
+		h := h
+		lookups[fmt.Sprintf("%s/%s|%s", h.repository, h.chart, tlsKey(h.tls))] = func() {
+			//nolint:errcheck // the error is returned again when the file is updated
+			r.getLatestVersion(h.repository, h.chart, h.tls)
 		}
 	}
-	res := result.NewResult("Helm")
+	return lookups, nil
+}
+
+func Update(fs afero.Fs, path string, r Repository, helmSelector *[]string) (*result.Result, error) {
+	hclFile, hclWriteFile, annos, err := util.ReadHCLFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read helm releases for %s: %w", path, err)
+	}
+	hh, err := parseHelmReleases(hclFile, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse helm releases for %s: %w", path, err)
+	}
+
+	res := result.NewResult(result.TitleHelm)
 	for _, h := range hh {
 		// Skip if the repository is not set as it mean the chart is local
 		if h.repository == "" {
 			continue
 		}
 
-		if _, ok := selector[h.chart]; helmSelector != nil && !ok {
-			res.Ignored = append(res.Ignored, &result.Ignore{Name: h.chart, Path: path})
-			continue
-		}
-		if annotation.ShouldSkipBlock(annos, h.blockRange) {
-			res.Ignored = append(res.Ignored, &result.Ignore{Name: h.chart, Path: path})
+		if reason := ignoreReason(h, annos, helmSelector); reason != "" {
+			res.Ignored = append(res.Ignored, &result.Ignore{Name: h.chart, Path: path, Reason: reason})
 			continue
 		}
 
-		latestVersion, err := r.getLatestVersion(h.repository, h.chart)
+		latest, err := r.getLatestVersion(h.repository, h.chart, h.tls)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get latest version of helm release %s - %s: %w", path, h.chart, err)
 		}
+		latestVersion := latest.Version
 		if h.version == latestVersion {
+			res.Current = append(res.Current, &result.Current{Name: h.chart, Path: path, Version: h.version})
 			continue
 		}
 
@@ -63,6 +85,14 @@ func Update(fs afero.Fs, path string, r Repository, helmSelector *[]string) (*re
 			Name:       h.chart,
 			OldVersion: h.version,
 			NewVersion: latestVersion,
+			ReleaseURL: releaseURL(h.chart, latest),
+			Occurrences: []*result.Occurrence{
+				{
+					Path:    path,
+					Address: fmt.Sprintf("helm_release.%s", h.name),
+					Range:   h.blockRange,
+				},
+			},
 		})
 	}
 
@@ -73,11 +103,32 @@ func Update(fs afero.Fs, path string, r Repository, helmSelector *[]string) (*re
 	return res, nil
 }
 
+// ignoreReason returns why the helm release is ignored, or an empty string if it should be updated.
+func ignoreReason(h *helmRelease, annos []*annotation.Annotation, helmSelector *[]string) string {
+	if helmSelector != nil && !contains(*helmSelector, h.chart) {
+		return result.IgnoreReasonSelector
+	}
+	if annotation.ShouldSkipBlock(annos, h.blockRange) {
+		return result.IgnoreReasonAnnotation
+	}
+	return ""
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 type helmRelease struct {
 	name       string
 	version    string
 	chart      string
 	repository string
+	tls        httpclient.TLSConfig
 	blockRange hcl.Range
 }
 
@@ -88,7 +139,17 @@ type helmReleaseResource struct {
 	Remain     hcl.Body `hcl:",remain"`
 }
 
-func parseHelmReleases(file *hcl.File) ([]*helmRelease, error) {
+var repositoryTLSSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "repository_ca_file"},
+		{Name: "repository_cert_file"},
+		{Name: "repository_key_file"},
+	},
+}
+
+// parseHelmReleases parses the helm releases of a file in the directory, which is used as path.module and to resolve
+// relative certificate paths.
+func parseHelmReleases(file *hcl.File, dir string) ([]*helmRelease, error) {
 	rootSchema := &hcl.BodySchema{
 		Blocks: []hcl.BlockHeaderSchema{
 			{
@@ -120,7 +181,7 @@ func parseHelmReleases(file *hcl.File) ([]*helmRelease, error) {
 		ctx := &hcl.EvalContext{
 			Variables: map[string]cty.Value{
 				"path": cty.MapVal(map[string]cty.Value{
-					"module": cty.StringVal("foobar"),
+					"module": cty.StringVal(dir),
 				}),
 			},
 		}
@@ -129,14 +190,54 @@ func parseHelmReleases(file *hcl.File) ([]*helmRelease, error) {
 			return []*helmRelease{}, errors.New(diags.Error())
 		}
 
+		// include the whole block and not only the definition line in the range
+		blockRange := block.DefRange
+		if body, ok := block.Body.(*hclsyntax.Body); ok {
+			blockRange = hcl.RangeBetween(block.DefRange, body.SrcRange)
+		}
+
 		hh = append(hh, &helmRelease{
 			name:       block.Labels[1],
 			version:    hrr.Version,
 			chart:      hrr.Chart,
 			repository: hrr.Repository,
-			blockRange: block.DefRange,
+			tls:        parseRepositoryTLS(hrr.Remain, ctx, dir),
+			blockRange: blockRange,
 		})
 	}
 
 	return hh, nil
 }
+
+// parseRepositoryTLS returns the repository certificate paths of the release. The attributes are evaluated separately
+// from the rest of the block as they are often set from variables, which are ignored so the flags are used instead.
+func parseRepositoryTLS(body hcl.Body, ctx *hcl.EvalContext, dir string) httpclient.TLSConfig {
+	content, _, _ := body.PartialContent(repositoryTLSSchema)
+	if content == nil {
+		return httpclient.TLSConfig{}
+	}
+	path := func(name string) string {
+		attr, ok := content.Attributes[name]
+		if !ok {
+			return ""
+		}
+		v, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() || v.IsNull() || !v.IsKnown() || v.Type() != cty.String {
+			return ""
+		}
+		return resolvePath(dir, v.AsString())
+	}
+	return httpclient.TLSConfig{
+		CAFile:   path("repository_ca_file"),
+		CertFile: path("repository_cert_file"),
+		KeyFile:  path("repository_key_file"),
+	}
+}
+
+// resolvePath returns the path relative to the directory unless it is empty or absolute.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
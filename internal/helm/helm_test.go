@@ -9,6 +9,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+	"github.com/xenitab/tf-provider-latest/internal/result"
+	"github.com/xenitab/tf-provider-latest/internal/util"
 )
 
 func createFs(content string) (afero.Fs, error) {
@@ -56,6 +60,7 @@ func TestBasic(t *testing.T) {
 				{
 					Metadata: &chart.Metadata{
 						Version: "3.0.3",
+						Home:    "https://github.com/Azure/aad-pod-identity",
 					},
 				},
 			},
@@ -67,10 +72,44 @@ func TestBasic(t *testing.T) {
 	require.NotEmpty(t, res.Updated, "result list can not be empty")
 	require.Equal(t, "aad-pod-identity", res.Updated[0].Name)
 	require.Equal(t, "3.0.3", res.Updated[0].NewVersion)
+	require.Equal(t, "https://github.com/Azure/aad-pod-identity/releases/tag/aad-pod-identity-3.0.3", res.Updated[0].ReleaseURL)
+	require.Len(t, res.Updated[0].Occurrences, 1)
+	require.Equal(t, "/tmp/terraform/main.tf", res.Updated[0].Occurrences[0].Path)
+	require.Equal(t, "helm_release.aad_pod_identity", res.Updated[0].Occurrences[0].Address)
+	require.Equal(t, 2, res.Updated[0].Occurrences[0].Range.Start.Line)
+	require.Equal(t, 7, res.Updated[0].Occurrences[0].Range.End.Line)
+
+	d, err := readFs(fs)
+	require.Nil(t, err)
+	require.Equal(t, basicTerraformExpected, d)
+}
+
+func TestReadOnlyBase(t *testing.T) {
+	base, err := createFs(basicTerraform)
+	require.Nil(t, err)
+	fs := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), afero.NewMemMapFs())
+
+	r := fakeRepository{
+		charts: map[string]repo.ChartVersions{
+			"aad-pod-identity": {
+				{
+					Metadata: &chart.Metadata{
+						Version: "3.0.3",
+					},
+				},
+			},
+		},
+	}
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil)
+	require.Nil(t, err)
+	require.NotEmpty(t, res.Updated)
 
 	d, err := readFs(fs)
 	require.Nil(t, err)
 	require.Equal(t, basicTerraformExpected, d)
+	d, err = readFs(base)
+	require.Nil(t, err)
+	require.Equal(t, basicTerraform, d)
 }
 
 func TestInvalidChart(t *testing.T) {
@@ -112,6 +151,7 @@ func TestIgnoreChart(t *testing.T) {
 	require.Nil(t, err)
 	require.Empty(t, res.Updated)
 	require.NotEmpty(t, res.Ignored)
+	require.Equal(t, result.IgnoreReasonAnnotation, res.Ignored[0].Reason)
 }
 
 func TestIgnoreFalsePositive(t *testing.T) {
@@ -162,12 +202,58 @@ func TestHelmSelector(t *testing.T) {
 	require.Nil(t, err)
 	require.NotEmpty(t, res.Updated)
 	require.Empty(t, res.Ignored)
+	require.Len(t, res.Current, 1)
+	require.Equal(t, "ingress-nginx", res.Current[0].Name)
 
 	d, err := readFs(fs)
 	require.Nil(t, err)
 	require.Equal(t, helmSelectorExpected, d)
 }
 
+func TestHelmLookups(t *testing.T) {
+	fs, err := createFs(helmSelector)
+	require.Nil(t, err)
+
+	helmSelector := []string{"aad-pod-identity"}
+	lookups, err := Lookups(fs, "/tmp/terraform/main.tf", fakeRepository{}, &helmSelector)
+	require.Nil(t, err)
+	require.Len(t, lookups, 1)
+	require.Contains(t, lookups, "https://raw.githubusercontent.com/Azure/aad-pod-identity/master/charts/aad-pod-identity|||")
+
+	fs, err = createFs(ignoreTerraform)
+	require.Nil(t, err)
+	lookups, err = Lookups(fs, "/tmp/terraform/main.tf", fakeRepository{}, nil)
+	require.Nil(t, err)
+	require.Empty(t, lookups)
+}
+
+func TestParseHelmReleaseTLS(t *testing.T) {
+	fs, err := createFs(tlsTerraform)
+	require.Nil(t, err)
+	hclFile, _, _, err := util.ReadHCLFile(fs, "/tmp/terraform/main.tf")
+	require.Nil(t, err)
+
+	hh, err := parseHelmReleases(hclFile, "/tmp/terraform")
+	require.Nil(t, err)
+	require.Len(t, hh, 2)
+	require.Equal(t, httpclient.TLSConfig{
+		CAFile:   "/tmp/terraform/certs/ca.pem",
+		CertFile: "/tmp/terraform/certs/client.pem",
+		KeyFile:  "/etc/certs/client-key.pem",
+	}, hh[0].tls)
+	require.Equal(t, httpclient.TLSConfig{}, hh[1].tls)
+
+	// certificates set from variables are ignored instead of failing the run
+	fs, err = createFs(tlsVariableTerraform)
+	require.Nil(t, err)
+	hclFile, _, _, err = util.ReadHCLFile(fs, "/tmp/terraform/main.tf")
+	require.Nil(t, err)
+	hh, err = parseHelmReleases(hclFile, "/tmp/terraform")
+	require.Nil(t, err)
+	require.Len(t, hh, 1)
+	require.Equal(t, httpclient.TLSConfig{CAFile: "/tmp/terraform/ca.pem"}, hh[0].tls)
+}
+
 const basicTerraform = `
 resource "helm_release" "aad_pod_identity" {
   repository = "https://raw.githubusercontent.com/Azure/aad-pod-identity/master/charts"
@@ -246,3 +332,34 @@ resource "helm_release" "ingress_nginx" {
   version    = "3.35.0"
 }
 `
+
+const tlsTerraform = `
+resource "helm_release" "internal" {
+  repository           = "https://charts.example.com"
+  repository_ca_file   = "${path.module}/certs/ca.pem"
+  repository_cert_file = "certs/client.pem"
+  repository_key_file  = "/etc/certs/client-key.pem"
+  chart                = "internal"
+  name                 = "internal"
+  version              = "1.0.0"
+}
+
+resource "helm_release" "public" {
+  repository = "https://charts.jetstack.io"
+  chart      = "cert-manager"
+  name       = "cert-manager"
+  version    = "v1.3.1"
+}
+`
+
+const tlsVariableTerraform = `
+resource "helm_release" "internal" {
+  repository           = "https://charts.example.com"
+  repository_ca_file   = "ca.pem"
+  repository_cert_file = "${path.root}/client.pem"
+  repository_key_file  = var.key_file
+  chart                = "internal"
+  name                 = "internal"
+  version              = "1.0.0"
+}
+`
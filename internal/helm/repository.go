@@ -3,97 +3,160 @@ package helm
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
-	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+	"github.com/xenitab/tf-provider-latest/internal/memo"
 )
 
 type Repository interface {
-	getLatestVersion(url, chart string) (string, error)
+	getLatestVersion(url, chart string, tlsConfig httpclient.TLSConfig) (*repo.ChartVersion, error)
 }
 
+// HelmRepository looks up charts in repositories, it is safe for concurrent use and downloads the index file of each
+// repository once.
 type HelmRepository struct {
-	cache map[string]string
+	client  *httpclient.Client
+	clients *memo.Cache[*httpclient.Client]
+	cache   *memo.Cache[*repo.ChartVersion]
+	indexes *memo.Cache[*repo.IndexFile]
 }
 
-func NewHelmRepository() HelmRepository {
+func NewHelmRepository(client *httpclient.Client) HelmRepository {
 	return HelmRepository{
-		cache: map[string]string{},
+		client:  client,
+		clients: memo.NewCache[*httpclient.Client](),
+		cache:   memo.NewCache[*repo.ChartVersion](),
+		indexes: memo.NewCache[*repo.IndexFile](),
 	}
 }
 
-func (h HelmRepository) getLatestVersion(url, chart string) (string, error) {
-	cacheKey := fmt.Sprintf("%s/%s", url, chart)
-	if v, ok := h.cache[cacheKey]; ok {
-		return v, nil
-	}
-
-	httpGetter := getter.Provider{
-		Schemes: []string{"https", "http"},
-		New:     getter.NewHTTPGetter,
-	}
-	entry := repo.Entry{
-		URL: url,
-	}
-	chartRepository, err := repo.NewChartRepository(&entry, getter.Providers{httpGetter})
-	if err != nil {
-		return "", err
-	}
+// getLatestVersion returns the latest stable version of the chart, the TLS config of the release is used in addition
+// to the TLS options of the client.
+func (h HelmRepository) getLatestVersion(url, chart string, tlsConfig httpclient.TLSConfig) (*repo.ChartVersion, error) {
+	// the repository can return different charts depending on the client certificate
+	cacheKey := fmt.Sprintf("%s/%s|%s", url, chart, tlsKey(tlsConfig))
+	return h.cache.Get(cacheKey, func() (*repo.ChartVersion, error) {
+		return h.fetchLatestVersion(url, chart, tlsConfig)
+	})
+}
 
-	path, err := chartRepository.DownloadIndexFile()
-	if err != nil {
-		return "", err
-	}
-	indexFile, err := repo.LoadIndexFile(path)
+func (h HelmRepository) fetchLatestVersion(url, chart string, tlsConfig httpclient.TLSConfig) (*repo.ChartVersion, error) {
+	indexFile, err := h.indexes.Get(fmt.Sprintf("%s|%s", url, tlsKey(tlsConfig)), func() (*repo.IndexFile, error) {
+		client, err := h.tlsClient(tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create client for %s: %w", url, err)
+		}
+		return getIndexFile(client, url)
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	indexFile.SortEntries()
 
 	chartVersions, ok := indexFile.Entries[chart]
 	if !ok {
-		return "", fmt.Errorf("could not find chart entry %q", chart)
+		return nil, fmt.Errorf("could not find chart entry %q", chart)
 	}
 
 	if len(chartVersions) == 0 {
-		return "", fmt.Errorf("chart %q does not have any versions", chart)
+		return nil, fmt.Errorf("chart %q does not have any versions", chart)
 	}
 
 	v, err := firstStableVersion(chartVersions)
 	if err != nil {
-		return "", fmt.Errorf("could not get a stable version: %w", err)
+		return nil, fmt.Errorf("could not get a stable version: %w", err)
 	}
-	h.cache[cacheKey] = v
 	return v, nil
 }
 
+// tlsClient returns the client of the repository, or a client with the TLS config if it is set.
+func (h HelmRepository) tlsClient(tlsConfig httpclient.TLSConfig) (*httpclient.Client, error) {
+	if tlsConfig == (httpclient.TLSConfig{}) {
+		return h.client, nil
+	}
+	return h.clients.Get(tlsKey(tlsConfig), func() (*httpclient.Client, error) {
+		return h.client.WithTLS(tlsConfig)
+	})
+}
+
+func tlsKey(tlsConfig httpclient.TLSConfig) string {
+	return fmt.Sprintf("%s|%s|%s", tlsConfig.CAFile, tlsConfig.CertFile, tlsConfig.KeyFile)
+}
+
+// getIndexFile downloads and parses the index file of the repository.
+func getIndexFile(client *httpclient.Client, url string) (*repo.IndexFile, error) {
+	r, err := client.Get(fmt.Sprintf("%s/index.yaml", strings.TrimSuffix(url, "/")))
+	if err != nil {
+		return nil, fmt.Errorf("unable to download index file of %s: %w", url, err)
+	}
+	defer r.Body.Close()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	indexFile := &repo.IndexFile{}
+	err = yaml.Unmarshal(b, indexFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse index file of %s: %w", url, err)
+	}
+	if indexFile.APIVersion == "" {
+		return nil, fmt.Errorf("index file of %s has no API version", url)
+	}
+	indexFile.SortEntries()
+	return indexFile, nil
+}
+
 type fakeRepository struct {
 	charts map[string]repo.ChartVersions
 }
 
-func (f fakeRepository) getLatestVersion(url, chart string) (string, error) {
+func (f fakeRepository) getLatestVersion(url, chart string, tlsConfig httpclient.TLSConfig) (*repo.ChartVersion, error) {
 	chartVersion, ok := f.charts[chart]
 	if !ok {
-		return "", fmt.Errorf("could not find chart entry %q", chart)
+		return nil, fmt.Errorf("could not find chart entry %q", chart)
 	}
 
 	return firstStableVersion(chartVersion)
 }
 
-func firstStableVersion(chartVersions repo.ChartVersions) (string, error) {
+func firstStableVersion(chartVersions repo.ChartVersions) (*repo.ChartVersion, error) {
 	for _, ch := range chartVersions {
 		v, err := semver.NewVersion(ch.Version)
 		if err != nil {
-			return "", fmt.Errorf("could not parse semver %q: %w", ch.Version, err)
+			return nil, fmt.Errorf("could not parse semver %q: %w", ch.Version, err)
 		}
 
 		if v.Prerelease() != "" {
 			continue
 		}
 
-		return ch.Version, nil
+		return ch, nil
 	}
 
-	return "", errors.New("no stable versions found")
+	return nil, errors.New("no stable versions found")
+}
+
+// releaseURL returns the GitHub release of the chart version, using the "<chart>-<version>" tag created by
+// chart-releaser, when the chart sources or home page are on GitHub. An empty string is returned otherwise as the
+// sources and home page are not release notes for the version.
+func releaseURL(chart string, ch *repo.ChartVersion) string {
+	for _, source := range append(append([]string{}, ch.Sources...), ch.Home) {
+		u, err := url.Parse(source)
+		if err != nil || u.Host != "github.com" {
+			continue
+		}
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) < 2 {
+			continue
+		}
+		return fmt.Sprintf("https://github.com/%s/%s/releases/tag/%s-%s", parts[0], parts[1], chart, ch.Version)
+	}
+	return ""
 }
@@ -1,11 +1,22 @@
 package helm
 
 import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
 )
 
 func TestFirstStableVersion(t *testing.T) {
@@ -29,7 +40,7 @@ func TestFirstStableVersion(t *testing.T) {
 
 	v, err := firstStableVersion(chartVersions)
 	require.NoError(t, err)
-	require.Equal(t, "0.0.1", v)
+	require.Equal(t, "0.0.1", v.Version)
 }
 
 func TestFirstStableVersionNone(t *testing.T) {
@@ -54,3 +65,121 @@ func TestFirstStableVersionNone(t *testing.T) {
 	_, err := firstStableVersion(chartVersions)
 	require.Error(t, err)
 }
+
+func TestReleaseURL(t *testing.T) {
+	ch := &repo.ChartVersion{
+		Metadata: &chart.Metadata{
+			Version: "4.0.1",
+			Home:    "https://github.com/kubernetes/ingress-nginx",
+			Sources: []string{"https://github.com/kubernetes/ingress-nginx/tree/main/charts/ingress-nginx"},
+		},
+	}
+	expected := "https://github.com/kubernetes/ingress-nginx/releases/tag/ingress-nginx-4.0.1"
+	require.Equal(t, expected, releaseURL("ingress-nginx", ch))
+
+	ch.Sources = nil
+	require.Equal(t, expected, releaseURL("ingress-nginx", ch))
+
+	// only GitHub repositories have release pages which can be linked
+	ch.Sources = []string{"https://gitlab.com/foo/bar"}
+	ch.Home = "https://example.com"
+	require.Empty(t, releaseURL("ingress-nginx", ch))
+}
+
+func TestHelmRepository(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		require.Equal(t, "/charts/index.yaml", r.URL.Path)
+		fmt.Fprint(w, helmIndex)
+	}))
+	defer srv.Close()
+
+	client := httpclient.NewClient(httpclient.Options{Retries: 1})
+	h := NewHelmRepository(client)
+	v, err := h.getLatestVersion(srv.URL+"/charts/", "cert-manager", httpclient.TLSConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "v1.9.1", v.Version)
+	require.Equal(t, 2, requests)
+
+	// the latest version is cached per chart
+	_, err = h.getLatestVersion(srv.URL+"/charts/", "cert-manager", httpclient.TLSConfig{})
+	require.NoError(t, err)
+	require.Equal(t, 2, requests)
+
+	// the index file is downloaded once per repository
+	_, err = h.getLatestVersion(srv.URL+"/charts/", "missing", httpclient.TLSConfig{})
+	require.Error(t, err)
+	require.Equal(t, 2, requests)
+}
+
+func TestHelmRepositoryConcurrent(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, helmIndex)
+	}))
+	defer srv.Close()
+
+	h := NewHelmRepository(httpclient.NewClient(httpclient.DefaultOptions()))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := h.getLatestVersion(srv.URL, "cert-manager", httpclient.TLSConfig{})
+			assert.NoError(t, err)
+			assert.Equal(t, "v1.9.1", v.Version)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestHelmRepositoryNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	h := NewHelmRepository(httpclient.NewClient(httpclient.Options{Retries: 1}))
+	_, err := h.getLatestVersion(srv.URL, "cert-manager", httpclient.TLSConfig{})
+	require.Error(t, err)
+}
+
+const helmIndex = `apiVersion: v1
+entries:
+  cert-manager:
+  - name: cert-manager
+    version: v1.10.0-alpha.0
+  - name: cert-manager
+    version: v1.8.2
+  - name: cert-manager
+    version: v1.9.1
+`
+
+func TestHelmRepositoryTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, helmIndex)
+	}))
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	err := afero.WriteFile(fs, "/tmp/terraform/ca.pem", caPEM, os.FileMode(0o644))
+	require.NoError(t, err)
+
+	client, err := httpclient.NewTLSClient(fs, httpclient.Options{})
+	require.NoError(t, err)
+	h := NewHelmRepository(client)
+	_, err = h.getLatestVersion(srv.URL, "cert-manager", httpclient.TLSConfig{})
+	require.Error(t, err)
+
+	// the CA file of the release is only used for its repository
+	v, err := h.getLatestVersion(srv.URL, "cert-manager", httpclient.TLSConfig{CAFile: "/tmp/terraform/ca.pem"})
+	require.NoError(t, err)
+	require.Equal(t, "v1.9.1", v.Version)
+	require.Equal(t, 1, h.clients.Len())
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// DefaultCacheTTL is how long responses are read from the cache before they are requested again.
+const DefaultCacheTTL = time.Hour
+
+// Cache stores response bodies on disk so that they can be shared between runs. Files are written to a temporary
+// file which is renamed into place, so parallel runs sharing the directory never read a partially written file.
+type Cache struct {
+	fs      afero.Fs
+	dir     string
+	ttl     time.Duration
+	refresh bool
+	now     func() time.Time
+}
+
+// NewCache returns a cache storing files in dir, cached files are ignored when older than ttl or if refresh is true.
+func NewCache(fs afero.Fs, dir string, ttl time.Duration, refresh bool) *Cache {
+	return &Cache{
+		fs:      fs,
+		dir:     dir,
+		ttl:     ttl,
+		refresh: refresh,
+		now:     time.Now,
+	}
+}
+
+// DefaultCacheDir returns the directory in the user cache directory, which is XDG_CACHE_HOME on Linux.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tf-latest-version"), nil
+}
+
+func cacheKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		// separate the parts so that different splits never result in the same key
+		fmt.Fprintf(h, "%d:%s", len(p), p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+func (c *Cache) get(key string) ([]byte, bool) {
+	if c.refresh {
+		return nil, false
+	}
+	info, err := c.fs.Stat(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	if c.now().Sub(info.ModTime()) > c.ttl {
+		return nil, false
+	}
+	b, err := afero.ReadFile(c.fs, c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+func (c *Cache) set(key string, b []byte) error {
+	err := c.fs.MkdirAll(c.dir, os.FileMode(0o755))
+	if err != nil {
+		return err
+	}
+	f, err := afero.TempFile(c.fs, c.dir, fmt.Sprintf("%s.*.tmp", key))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		//nolint:errcheck // ignore as the write error is more relevant
+		c.fs.Remove(f.Name())
+		return err
+	}
+	return c.fs.Rename(f.Name(), c.path(key))
+}
@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func newCountingServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, "response %d", requests)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func getBody(t *testing.T, c *Client, u string) string {
+	t.Helper()
+
+	resp, err := c.Get(u)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestClientCache(t *testing.T) {
+	srv, requests := newCountingServer(t)
+	fs := afero.NewMemMapFs()
+	cache := NewCache(fs, "/cache", time.Hour, false)
+	c := NewClient(Options{Cache: cache})
+
+	require.Equal(t, "response 1", getBody(t, c, srv.URL))
+	require.Equal(t, "response 1", getBody(t, c, srv.URL))
+	require.Equal(t, 1, *requests)
+
+	// a second client sharing the directory reads the same file
+	other := NewClient(Options{Cache: NewCache(fs, "/cache", time.Hour, false)})
+	require.Equal(t, "response 1", getBody(t, other, srv.URL))
+	require.Equal(t, 1, *requests)
+
+	// no temporary files are left behind
+	infos, err := afero.ReadDir(fs, "/cache")
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+
+	// expired files are requested again
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	require.Equal(t, "response 2", getBody(t, c, srv.URL))
+	require.Equal(t, 2, *requests)
+}
+
+func TestClientCacheRefresh(t *testing.T) {
+	srv, requests := newCountingServer(t)
+	fs := afero.NewMemMapFs()
+	c := NewClient(Options{Cache: NewCache(fs, "/cache", time.Hour, false)})
+	require.Equal(t, "response 1", getBody(t, c, srv.URL))
+
+	refresh := NewClient(Options{Cache: NewCache(fs, "/cache", time.Hour, true)})
+	require.Equal(t, "response 2", getBody(t, refresh, srv.URL))
+	require.Equal(t, 2, *requests)
+
+	// the refreshed response is written to the cache
+	require.Equal(t, "response 2", getBody(t, c, srv.URL))
+	require.Equal(t, 2, *requests)
+}
+
+func TestClientCacheAuthorization(t *testing.T) {
+	srv, requests := newCountingServer(t)
+	c := NewClient(Options{Cache: NewCache(afero.NewMemMapFs(), "/cache", time.Hour, false)})
+
+	for _, token := range []string{"foo", "bar", "foo"} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, http.NoBody)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		resp, err := c.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	require.Equal(t, 2, *requests)
+}
+
+func TestClientCacheTLS(t *testing.T) {
+	srv, requests := newCountingServer(t)
+	cache := NewCache(afero.NewMemMapFs(), "/cache", time.Hour, false)
+	c := NewClient(Options{Cache: cache})
+	require.Equal(t, "response 1", getBody(t, c, srv.URL))
+
+	// responses fetched with another client certificate are not shared
+	tlsClient := NewClient(Options{Cache: cache, TLS: TLSConfig{CertFile: "client.pem", KeyFile: "client-key.pem"}})
+	require.Equal(t, "response 2", getBody(t, tlsClient, srv.URL))
+	other := NewClient(Options{Cache: cache, TLS: TLSConfig{CertFile: "other.pem", KeyFile: "other-key.pem"}})
+	require.Equal(t, "response 3", getBody(t, other, srv.URL))
+	require.Equal(t, "response 2", getBody(t, tlsClient, srv.URL))
+	require.Equal(t, 3, *requests)
+}
+
+func TestClientCacheErrors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	fs := afero.NewMemMapFs()
+	c := NewClient(Options{Cache: NewCache(fs, "/cache", time.Hour, false)})
+
+	_, err := c.Get(srv.URL)
+	require.Error(t, err)
+	exists, err := afero.DirExists(fs, "/cache")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestCacheConcurrentWrites(t *testing.T) {
+	fs := afero.NewOsFs()
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache := NewCache(fs, dir, time.Hour, false)
+			err := cache.set("key", []byte(strings.Repeat(fmt.Sprint(i), 1024)))
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	b, ok := NewCache(fs, dir, time.Hour, false).get("key")
+	require.True(t, ok)
+	require.Len(t, b, 1024)
+	require.Equal(t, strings.Repeat(string(b[0]), 1024), string(b))
+	infos, err := afero.ReadDir(fs, dir)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+}
@@ -0,0 +1,223 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	DefaultRetries    = 3
+	DefaultTimeout    = 10 * time.Second
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Options configures the retries and timeouts of the client.
+type Options struct {
+	// Retries is the number of times a request is retried after the first attempt.
+	Retries int
+	// Timeout is the timeout of a single attempt.
+	Timeout time.Duration
+	// MinBackoff and MaxBackoff bound the exponential backoff between attempts, MaxBackoff also limits Retry-After.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Transport is used to send the requests, the default transport is used if nil.
+	Transport http.RoundTripper
+	// Cache stores the bodies of successful GET responses, nothing is cached if nil.
+	Cache *Cache
+	// TLS configures the transport created by NewTLSClient, it is not used if Transport is set.
+	TLS TLSConfig
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Retries:    DefaultRetries,
+		Timeout:    DefaultTimeout,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// Client sends requests which are retried with exponential backoff on network errors, 429 and 5xx responses.
+type Client struct {
+	client *http.Client
+	fs     afero.Fs
+	opts   Options
+	sleep  func(time.Duration)
+	now    func() time.Time
+}
+
+func NewClient(opts Options) *Client {
+	return newClient(afero.NewOsFs(), opts, opts.Transport)
+}
+
+// NewTLSClient returns a client which uses the TLS options, reading the certificates from fs, and the proxy set in
+// the environment.
+func NewTLSClient(fs afero.Fs, opts Options) (*Client, error) {
+	transport := opts.Transport
+	if transport == nil {
+		t, err := newTransport(fs, opts.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport = t
+	}
+	return newClient(fs, opts, transport), nil
+}
+
+func newClient(fs afero.Fs, opts Options, transport http.RoundTripper) *Client {
+	return &Client{
+		client: &http.Client{Timeout: opts.Timeout, Transport: transport},
+		fs:     fs,
+		opts:   opts,
+		sleep:  time.Sleep,
+		now:    time.Now,
+	}
+}
+
+// WithTLS returns a client with the same options where the values set in cfg replace the TLS options of the client.
+func (c *Client) WithTLS(cfg TLSConfig) (*Client, error) {
+	opts := c.opts
+	opts.TLS = opts.TLS.merge(cfg)
+	return NewTLSClient(c.fs, opts)
+}
+
+// StatusError is returned when the final response does not have a 2xx status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request to %s failed with status %s", e.URL, e.Status)
+}
+
+// Get sends a GET request to the URL.
+func (c *Client) Get(u string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends the request until it succeeds or the retries are used up, a response is only returned for a 2xx status.
+// Requests are sent multiple times so they must not have a body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.opts.Cache == nil || req.Method != http.MethodGet {
+		return c.do(req)
+	}
+
+	// responses can differ per token and client certificate so they are part of the key
+	key := cacheKey(req.URL.String(), req.Header.Get("Authorization"), c.opts.TLS.CAFile, c.opts.TLS.CertFile, c.opts.TLS.KeyFile)
+	if b, ok := c.opts.Cache.get(key); ok {
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{},
+			Body:          io.NopCloser(bytes.NewReader(b)),
+			ContentLength: int64(len(b)),
+			Request:       req,
+		}, nil
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// failing to write the cache only means that the next run sends the request again
+	//nolint:errcheck // ignore as the response is still valid
+	c.opts.Cache.set(key, b)
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return resp, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.client.Do(req.Clone(req.Context()))
+		if err != nil {
+			if attempt >= c.opts.Retries {
+				return nil, err
+			}
+			c.sleep(c.backoff(attempt))
+			continue
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		wait, retry := c.retryWait(resp, attempt)
+		// the body has to be read for the connection to be reused
+		//nolint:errcheck // ignore as the response is discarded
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if !retry {
+			return nil, &StatusError{URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		c.sleep(wait)
+	}
+}
+
+// retryWait returns how long to wait before retrying the response, or false if it should not be retried.
+func (c *Client) retryWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if !retryable(resp.StatusCode) || attempt >= c.opts.Retries {
+		return 0, false
+	}
+	d, ok := retryAfter(resp.Header.Get("Retry-After"), c.now())
+	if !ok {
+		return c.backoff(attempt), true
+	}
+	// waiting longer than the max backoff would stall the whole run, so the retry is sent earlier
+	if d > c.opts.MaxBackoff {
+		d = c.opts.MaxBackoff
+	}
+	return d, true
+}
+
+// backoff returns the exponential backoff for the attempt with jitter in the upper half of the interval.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.opts.MinBackoff << attempt
+	if d <= 0 || d > c.opts.MaxBackoff {
+		d = c.opts.MaxBackoff
+	}
+	if d <= 1 {
+		return d
+	}
+	//nolint:gosec // jitter does not need a secure random number
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
+
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// retryAfter parses the Retry-After header which is either a number of seconds or a HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(retries int) (*Client, *[]time.Duration) {
+	waits := []time.Duration{}
+	c := NewClient(Options{
+		Retries:    retries,
+		Timeout:    time.Second,
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	})
+	c.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+	}
+	return c, &waits
+}
+
+func TestClientRetry(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(3)
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "ok", string(b))
+	require.Equal(t, 3, requests)
+	require.Len(t, *waits, 2)
+	require.GreaterOrEqual(t, (*waits)[0], 50*time.Millisecond)
+	require.Less(t, (*waits)[0], 100*time.Millisecond)
+	require.GreaterOrEqual(t, (*waits)[1], 100*time.Millisecond)
+	require.Less(t, (*waits)[1], 200*time.Millisecond)
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(3)
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, []time.Duration{2 * time.Second}, *waits)
+}
+
+func TestClientRetryAfterCapped(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(3)
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, []time.Duration{10 * time.Second}, *waits)
+}
+
+func TestClientRetriesExhausted(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(2)
+	_, err := c.Get(srv.URL)
+	var statusErr *StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	require.Equal(t, 3, requests)
+}
+
+func TestClientNoRetryOnClientError(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(3)
+	_, err := c.Get(srv.URL)
+	var statusErr *StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	require.Equal(t, 1, requests)
+	require.Empty(t, *waits)
+}
+
+func TestClientNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	c, waits := newTestClient(1)
+	_, err := c.Get(srv.URL)
+	require.Error(t, err)
+	require.Len(t, *waits, 1)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	d, ok := retryAfter("5", now)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Second, d)
+	d, ok = retryAfter("Sat, 01 Jan 2022 12:00:30 GMT", now)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, d)
+	_, ok = retryAfter("soon", now)
+	require.False(t, ok)
+	_, ok = retryAfter("", now)
+	require.False(t, ok)
+}
@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/afero"
+)
+
+// TLSConfig contains the paths of the PEM encoded CA bundle and client certificate used for requests.
+type TLSConfig struct {
+	// CAFile is a CA bundle which is trusted in addition to the system roots.
+	CAFile string
+	// CertFile and KeyFile are the client certificate and key sent for mutual TLS.
+	CertFile string
+	KeyFile  string
+}
+
+// merge returns the config with the values which are set in other replacing its own.
+func (t TLSConfig) merge(other TLSConfig) TLSConfig {
+	if other.CAFile != "" {
+		t.CAFile = other.CAFile
+	}
+	if other.CertFile != "" {
+		t.CertFile = other.CertFile
+	}
+	if other.KeyFile != "" {
+		t.KeyFile = other.KeyFile
+	}
+	return t
+}
+
+// newTransport returns a copy of the default transport using the TLS config, proxies are read from the HTTPS_PROXY,
+// HTTP_PROXY and NO_PROXY environment variables.
+func newTransport(fs afero.Fs, cfg TLSConfig) (*http.Transport, error) {
+	//nolint:forcetypeassert // the default transport is always a http.Transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		b, err := afero.ReadFile(fs, cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, errors.New("both the client certificate and key have to be set")
+		}
+		certPEM, err := afero.ReadFile(fs, cfg.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client certificate: %w", err)
+		}
+		keyPEM, err := afero.ReadFile(fs, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client key: %w", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
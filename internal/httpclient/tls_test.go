@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSClientCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	err := afero.WriteFile(fs, "/certs/ca.pem", caPEM, os.FileMode(0o644))
+	require.NoError(t, err)
+
+	// the server certificate is not trusted without the CA file
+	client, err := NewTLSClient(fs, Options{})
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	require.Error(t, err)
+
+	client, err = NewTLSClient(fs, Options{TLS: TLSConfig{CAFile: "/certs/ca.pem"}})
+	require.NoError(t, err)
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = NewTLSClient(fs, Options{TLS: TLSConfig{CAFile: "/certs/missing.pem"}})
+	require.Error(t, err)
+	err = afero.WriteFile(fs, "/certs/invalid.pem", []byte("foobar"), os.FileMode(0o644))
+	require.NoError(t, err)
+	_, err = NewTLSClient(fs, Options{TLS: TLSConfig{CAFile: "/certs/invalid.pem"}})
+	require.Error(t, err)
+}
+
+func TestTLSClientCertificate(t *testing.T) {
+	certPEM, keyPEM := generateCertificate(t)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(certPEM))
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	srv.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, afero.WriteFile(fs, "/certs/ca.pem", caPEM, os.FileMode(0o644)))
+	require.NoError(t, afero.WriteFile(fs, "/certs/client.pem", certPEM, os.FileMode(0o644)))
+	require.NoError(t, afero.WriteFile(fs, "/certs/client-key.pem", keyPEM, os.FileMode(0o600)))
+
+	client, err := NewTLSClient(fs, Options{TLS: TLSConfig{CAFile: "/certs/ca.pem"}})
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	require.Error(t, err)
+
+	// the client certificate is added to the TLS options of the client
+	client, err = client.WithTLS(TLSConfig{CertFile: "/certs/client.pem", KeyFile: "/certs/client-key.pem"})
+	require.NoError(t, err)
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	_, err = NewTLSClient(fs, Options{TLS: TLSConfig{CertFile: "/certs/client.pem"}})
+	require.Error(t, err)
+}
+
+func TestTLSConfigMerge(t *testing.T) {
+	base := TLSConfig{CAFile: "ca.pem", CertFile: "cert.pem", KeyFile: "key.pem"}
+	require.Equal(t, base, base.merge(TLSConfig{}))
+	require.Equal(t, TLSConfig{CAFile: "other.pem", CertFile: "cert.pem", KeyFile: "key.pem"}, base.merge(TLSConfig{CAFile: "other.pem"}))
+}
+
+func generateCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
@@ -0,0 +1,45 @@
+package memo
+
+import "sync"
+
+// Cache memoizes the result of a lookup per key and is safe for concurrent use. Concurrent lookups of the same key
+// wait for the first one instead of looking it up again, and errors are memoized as well.
+type Cache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]*entry[V]
+}
+
+type entry[V any] struct {
+	once  sync.Once
+	value V
+	err   error
+}
+
+func NewCache[V any]() *Cache[V] {
+	return &Cache[V]{
+		entries: map[string]*entry[V]{},
+	}
+}
+
+// Get returns the memoized result for the key, calling fn if the key has not been looked up yet.
+func (c *Cache[V]) Get(key string, fn func() (V, error)) (V, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &entry[V]{}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+
+	e.once.Do(func() {
+		e.value, e.err = fn()
+	})
+	return e.value, e.err
+}
+
+// Len returns the number of memoized keys.
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,60 @@
+package memo
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	c := NewCache[string]()
+	calls := 0
+	fn := func() (string, error) {
+		calls++
+		return "bar", nil
+	}
+	v, err := c.Get("foo", fn)
+	require.NoError(t, err)
+	require.Equal(t, "bar", v)
+	v, err = c.Get("foo", fn)
+	require.NoError(t, err)
+	require.Equal(t, "bar", v)
+	require.Equal(t, 1, calls)
+	require.Equal(t, 1, c.Len())
+}
+
+func TestCacheError(t *testing.T) {
+	c := NewCache[string]()
+	calls := 0
+	fn := func() (string, error) {
+		calls++
+		return "", errors.New("foo")
+	}
+	_, err := c.Get("foo", fn)
+	require.Error(t, err)
+	_, err = c.Get("foo", fn)
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := NewCache[int]()
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get("foo", func() (int, error) {
+				return int(atomic.AddInt32(&calls, 1)), nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, v)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), calls)
+}
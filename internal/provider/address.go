@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	TerraformRegistryHost = "registry.terraform.io"
+	OpenTofuRegistryHost  = "registry.opentofu.org"
+	defaultNamespace      = "hashicorp"
+)
+
+type address struct {
+	hostname  string
+	namespace string
+	name      string
+}
+
+// parseAddress parses a provider source address in the form [hostname/]namespace/type.
+// Sources without hostname use the default hostname and sources with only a type use the hashicorp namespace.
+func parseAddress(source, defaultHost string) (address, error) {
+	parts := strings.Split(strings.ToLower(source), "/")
+	for _, part := range parts {
+		if part == "" {
+			return address{}, fmt.Errorf("invalid provider source %q", source)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return address{hostname: defaultHost, namespace: defaultNamespace, name: parts[0]}, nil
+	case 2:
+		return address{hostname: defaultHost, namespace: parts[0], name: parts[1]}, nil
+	case 3:
+		return address{hostname: parts[0], namespace: parts[1], name: parts[2]}, nil
+	default:
+		return address{}, fmt.Errorf("invalid provider source %q", source)
+	}
+}
+
+func (a address) String() string {
+	return fmt.Sprintf("%s/%s/%s", a.hostname, a.namespace, a.name)
+}
+
+// matches returns true if the address matches the pattern, where the namespace and type of the pattern can be a wildcard.
+func (a address) matches(addr address) bool {
+	return a.hostname == addr.hostname &&
+		(a.namespace == "*" || a.namespace == addr.namespace) &&
+		(a.name == "*" || a.name == addr.name)
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected address
+	}{
+		{
+			source:   "azurerm",
+			expected: address{hostname: TerraformRegistryHost, namespace: "hashicorp", name: "azurerm"},
+		},
+		{
+			source:   "hashicorp/azurerm",
+			expected: address{hostname: TerraformRegistryHost, namespace: "hashicorp", name: "azurerm"},
+		},
+		{
+			source:   "app.terraform.io/Acme/Internal",
+			expected: address{hostname: "app.terraform.io", namespace: "acme", name: "internal"},
+		},
+		{
+			source:   "tf.corp.example:8443/ns/name",
+			expected: address{hostname: "tf.corp.example:8443", namespace: "ns", name: "name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			addr, err := parseAddress(tt.source, TerraformRegistryHost)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, addr)
+		})
+	}
+}
+
+func TestParseAddressInvalid(t *testing.T) {
+	for _, source := range []string{"", "hashicorp//azurerm", "a/b/c/d"} {
+		t.Run(source, func(t *testing.T) {
+			_, err := parseAddress(source, TerraformRegistryHost)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// FilesystemMirrorRegistry resolves versions from a local directory using the packed or unpacked filesystem mirror layout.
+type FilesystemMirrorRegistry struct {
+	fs          afero.Fs
+	path        string
+	defaultHost string
+}
+
+func NewFilesystemMirrorRegistry(fs afero.Fs, path, defaultHost string) FilesystemMirrorRegistry {
+	return FilesystemMirrorRegistry{
+		fs:          fs,
+		path:        path,
+		defaultHost: defaultHost,
+	}
+}
+
+func (f FilesystemMirrorRegistry) getVersions(name string) (*release, error) {
+	addr, err := parseAddress(name, f.defaultHost)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(f.path, addr.hostname, addr.namespace, addr.name)
+	infos, err := afero.ReadDir(f.fs, dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("provider %q not found in filesystem mirror %s", name, f.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rel := &release{versions: []*releaseVersion{}}
+	versions := map[string]*releaseVersion{}
+	addPlatform := func(version, platform string) {
+		rv, ok := versions[version]
+		if !ok {
+			rv = &releaseVersion{version: version, platforms: []string{}}
+			versions[version] = rv
+			rel.versions = append(rel.versions, rv)
+		}
+		rv.platforms = append(rv.platforms, platform)
+	}
+	for _, info := range infos {
+		// unpacked layout uses a directory per version containing a directory per platform
+		if info.IsDir() {
+			platformInfos, err := afero.ReadDir(f.fs, filepath.Join(dir, info.Name()))
+			if err != nil {
+				return nil, err
+			}
+			for _, platformInfo := range platformInfos {
+				if platformInfo.IsDir() {
+					addPlatform(info.Name(), platformInfo.Name())
+				}
+			}
+			continue
+		}
+		// packed layout uses an archive per version and platform
+		if v, platform, ok := parsePackedArchive(addr.name, info.Name()); ok {
+			addPlatform(v, platform)
+		}
+	}
+	return rel, nil
+}
+
+// parsePackedArchive returns the version and platform from an archive named terraform-provider-TYPE_VERSION_OS_ARCH.zip.
+func parsePackedArchive(providerType, fileName string) (version, platform string, ok bool) {
+	prefix := fmt.Sprintf("terraform-provider-%s_", providerType)
+	if !strings.HasPrefix(fileName, prefix) || filepath.Ext(fileName) != ".zip" {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(fileName, prefix), ".zip"), "_")
+	if len(parts) != 3 {
+		return "", "", false
+	}
+	return parts[0], fmt.Sprintf("%s_%s", parts[1], parts[2]), true
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesystemMirrorRegistryUnpacked(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for _, dir := range []string{
+		"/mirror/registry.terraform.io/hashicorp/azurerm/2.35.0/linux_amd64",
+		"/mirror/registry.terraform.io/hashicorp/azurerm/2.53.0/linux_amd64",
+		"/mirror/registry.terraform.io/hashicorp/azurerm/3.0.0-beta1/linux_amd64",
+	} {
+		err := fs.MkdirAll(dir, os.FileMode(0755))
+		require.NoError(t, err)
+	}
+
+	reg := NewFilesystemMirrorRegistry(fs, "/mirror", TerraformRegistryHost)
+	rel, err := reg.getVersions("hashicorp/azurerm")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"2.35.0", "2.53.0", "3.0.0-beta1"}, versionNames(rel))
+	require.Equal(t, []string{"linux_amd64"}, rel.versions[0].platforms)
+
+	_, err = reg.getVersions("hashicorp/aws")
+	require.Error(t, err)
+}
+
+func TestFilesystemMirrorRegistryPacked(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for _, file := range []string{
+		"/mirror/tf.corp.example/acme/internal/terraform-provider-internal_1.2.3_linux_amd64.zip",
+		"/mirror/tf.corp.example/acme/internal/terraform-provider-internal_1.10.0_darwin_arm64.zip",
+		"/mirror/tf.corp.example/acme/internal/terraform-provider-other_2.0.0_linux_amd64.zip",
+		"/mirror/tf.corp.example/acme/internal/README.md",
+	} {
+		err := afero.WriteFile(fs, file, []byte{}, os.FileMode(0644))
+		require.NoError(t, err)
+	}
+
+	reg := NewFilesystemMirrorRegistry(fs, "/mirror", TerraformRegistryHost)
+	rel, err := reg.getVersions("tf.corp.example/acme/internal")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"1.2.3", "1.10.0"}, versionNames(rel))
+	for _, v := range rel.versions {
+		if v.version == "1.10.0" {
+			require.Equal(t, []string{"darwin_arm64"}, v.platforms)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/spf13/afero"
+
+	"github.com/xenitab/tf-provider-latest/internal/cliconfig"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+	"github.com/xenitab/tf-provider-latest/internal/memo"
+)
+
+// NewRegistry returns the registry to use for the provider installation methods in the CLI config.
+// Without any methods providers are resolved directly from their registry.
+func NewRegistry(fs afero.Fs, client *httpclient.Client, cliConfig *cliconfig.Config, defaultHost string) (Registry, error) {
+	direct := newDirectRegistry(client, cliConfig, defaultHost)
+	if cliConfig == nil || len(cliConfig.ProviderInstallation) == 0 {
+		return direct, nil
+	}
+
+	multi := MultiRegistry{
+		defaultHost: defaultHost,
+		sources:     []*registrySource{},
+	}
+	for _, method := range cliConfig.ProviderInstallation {
+		var reg Registry
+		switch method.Type {
+		case cliconfig.InstallationMethodDirect:
+			reg = direct
+		case cliconfig.InstallationMethodNetworkMirror:
+			mirror, err := NewNetworkMirrorRegistry(client, cliConfig, method.URL, defaultHost)
+			if err != nil {
+				return nil, err
+			}
+			reg = mirror
+		case cliconfig.InstallationMethodFilesystemMirror:
+			reg = NewFilesystemMirrorRegistry(fs, method.Path, defaultHost)
+		default:
+			return nil, fmt.Errorf("unsupported provider installation method %q", method.Type)
+		}
+
+		source, err := newRegistrySource(reg, method, defaultHost)
+		if err != nil {
+			return nil, err
+		}
+		multi.sources = append(multi.sources, source)
+	}
+	return multi, nil
+}
+
+func newDirectRegistry(client *httpclient.Client, cliConfig *cliconfig.Config, defaultHost string) Registry {
+	if defaultHost == OpenTofuRegistryHost {
+		return NewOpenTofuRegistry(client, cliConfig)
+	}
+	return NewHashicorpRegistry(client, cliConfig, defaultHost)
+}
+
+// MultiRegistry resolves each provider with all of the installation methods which match it.
+type MultiRegistry struct {
+	defaultHost string
+	sources     []*registrySource
+}
+
+type registrySource struct {
+	registry Registry
+	include  []address
+	exclude  []address
+}
+
+func newRegistrySource(reg Registry, method *cliconfig.InstallationMethod, defaultHost string) (*registrySource, error) {
+	source := &registrySource{
+		registry: reg,
+		include:  []address{},
+		exclude:  []address{},
+	}
+	for _, pattern := range method.Include {
+		addr, err := parseAddress(pattern, defaultHost)
+		if err != nil {
+			return nil, err
+		}
+		source.include = append(source.include, addr)
+	}
+	for _, pattern := range method.Exclude {
+		addr, err := parseAddress(pattern, defaultHost)
+		if err != nil {
+			return nil, err
+		}
+		source.exclude = append(source.exclude, addr)
+	}
+	return source, nil
+}
+
+// getVersions combines the versions of every installation method which matches the provider, like Terraform does.
+// A method which fails is ignored as long as another method returns the versions.
+func (m MultiRegistry) getVersions(name string) (*release, error) {
+	addr, err := parseAddress(name, m.defaultHost)
+	if err != nil {
+		return nil, err
+	}
+	var combined *release
+	var firstErr error
+	for _, source := range m.sources {
+		if !source.matches(addr) {
+			continue
+		}
+		rel, err := source.registry.getVersions(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		combined = mergeReleases(combined, rel)
+	}
+	if combined != nil {
+		return combined, nil
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, fmt.Errorf("no provider installation method matches %q", name)
+}
+
+// mergeReleases returns the union of the versions, the platforms of a version are only known if every release lists them.
+func mergeReleases(a, b *release) *release {
+	if a == nil {
+		return b
+	}
+	merged := &release{
+		versions: []*releaseVersion{},
+		lookupSource: func() string {
+			if source := a.getSource(); source != "" {
+				return source
+			}
+			return b.getSource()
+		},
+	}
+	byVersion := map[string]*releaseVersion{}
+	for _, rv := range append(append([]*releaseVersion{}, a.versions...), b.versions...) {
+		existing, ok := byVersion[rv.version]
+		if !ok {
+			existing = &releaseVersion{version: rv.version, platforms: rv.platforms}
+			byVersion[rv.version] = existing
+			merged.versions = append(merged.versions, existing)
+			continue
+		}
+		if existing.platforms == nil || rv.platforms == nil {
+			existing.platforms = nil
+			continue
+		}
+		existing.platforms = unionStrings(existing.platforms, rv.platforms)
+	}
+	return merged
+}
+
+func unionStrings(a, b []string) []string {
+	union := append([]string{}, a...)
+	for _, s := range b {
+		if !contains(union, s) {
+			union = append(union, s)
+		}
+	}
+	return union
+}
+
+// matches returns true if the address matches any include pattern, or there are none, and does not match an exclude pattern.
+func (s *registrySource) matches(addr address) bool {
+	for _, pattern := range s.exclude {
+		if pattern.matches(addr) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, pattern := range s.include {
+		if pattern.matches(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registries creates the registry of each default host once and shares the resolved versions between them,
+// so that a provider gets the same versions in every file of a run.
+type Registries struct {
+	fs         afero.Fs
+	client     *httpclient.Client
+	cliConfig  *cliconfig.Config
+	registries *memo.Cache[Registry]
+	cache      *memo.Cache[*release]
+}
+
+func NewRegistries(fs afero.Fs, client *httpclient.Client, cliConfig *cliconfig.Config) *Registries {
+	return &Registries{
+		fs:         fs,
+		client:     client,
+		cliConfig:  cliConfig,
+		registries: memo.NewCache[Registry](),
+		cache:      memo.NewCache[*release](),
+	}
+}
+
+// Get returns the registry resolving sources without hostname against defaultHost, it is safe for concurrent use.
+func (r *Registries) Get(defaultHost string) (Registry, error) {
+	return r.registries.Get(defaultHost, func() (Registry, error) {
+		reg, err := NewRegistry(r.fs, r.client, r.cliConfig, defaultHost)
+		if err != nil {
+			return nil, err
+		}
+		return cachedRegistry{
+			registry:    reg,
+			defaultHost: defaultHost,
+			cache:       r.cache,
+		}, nil
+	})
+}
+
+// cachedRegistry caches versions by the fully qualified address so the cache can be shared between default hosts.
+type cachedRegistry struct {
+	registry    Registry
+	defaultHost string
+	cache       *memo.Cache[*release]
+}
+
+func (c cachedRegistry) getVersions(name string) (*release, error) {
+	addr, err := parseAddress(name, c.defaultHost)
+	if err != nil {
+		return nil, err
+	}
+	return c.cache.Get(addr.String(), func() (*release, error) {
+		return c.registry.getVersions(name)
+	})
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xenitab/tf-provider-latest/internal/cliconfig"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+)
+
+func TestNewRegistry(t *testing.T) {
+	reg, err := NewRegistry(afero.NewMemMapFs(), httpclient.NewClient(httpclient.DefaultOptions()), nil, TerraformRegistryHost)
+	require.NoError(t, err)
+	require.IsType(t, HashicorpRegistry{}, reg)
+
+	reg, err = NewRegistry(afero.NewMemMapFs(), httpclient.NewClient(httpclient.DefaultOptions()), cliconfig.NewConfig(), OpenTofuRegistryHost)
+	require.NoError(t, err)
+	require.IsType(t, OpenTofuRegistry{}, reg)
+
+	cfg := cliconfig.NewConfig()
+	cfg.ProviderInstallation = []*cliconfig.InstallationMethod{
+		{Type: cliconfig.InstallationMethodNetworkMirror, URL: "https://mirror.example.com/"},
+		{Type: cliconfig.InstallationMethodFilesystemMirror, Path: "/mirror"},
+	}
+	reg, err = NewRegistry(afero.NewMemMapFs(), httpclient.NewClient(httpclient.DefaultOptions()), cfg, TerraformRegistryHost)
+	require.NoError(t, err)
+	require.IsType(t, MultiRegistry{}, reg)
+}
+
+func TestMultiRegistry(t *testing.T) {
+	method := &cliconfig.InstallationMethod{
+		Include: []string{"hashicorp/*"},
+		Exclude: []string{"hashicorp/aws"},
+	}
+	mirror, err := newRegistrySource(FakeRegistry{providers: map[string][]string{"hashicorp/azurerm": {"2.53.0"}}}, method, TerraformRegistryHost)
+	require.NoError(t, err)
+	direct, err := newRegistrySource(FakeRegistry{providers: map[string][]string{"hashicorp/aws": {"3.59.0"}}}, &cliconfig.InstallationMethod{}, TerraformRegistryHost)
+	require.NoError(t, err)
+	reg := MultiRegistry{
+		defaultHost: TerraformRegistryHost,
+		sources:     []*registrySource{mirror, direct},
+	}
+
+	rel, err := reg.getVersions("hashicorp/azurerm")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2.53.0"}, versionNames(rel))
+	rel, err = reg.getVersions("hashicorp/aws")
+	require.NoError(t, err)
+	require.Equal(t, []string{"3.59.0"}, versionNames(rel))
+
+	// versions from every matching method are combined
+	newer := FakeRegistry{providers: map[string][]string{"hashicorp/azurerm": {"2.53.0", "2.54.0"}}}
+	other, err := newRegistrySource(newer, &cliconfig.InstallationMethod{}, TerraformRegistryHost)
+	require.NoError(t, err)
+	reg.sources = []*registrySource{mirror, direct, other}
+	rel, err = reg.getVersions("hashicorp/azurerm")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2.53.0", "2.54.0"}, versionNames(rel))
+
+	reg.sources = []*registrySource{direct}
+	_, err = reg.getVersions("hashicorp/azurerm")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+
+	reg.sources = []*registrySource{mirror}
+	_, err = reg.getVersions("hashicorp/aws")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no provider installation method matches")
+}
+
+type countingRegistry struct {
+	versions map[string][]string
+	requests *int
+}
+
+func (c countingRegistry) getVersions(name string) (*release, error) {
+	*c.requests++
+	versions := []*releaseVersion{}
+	for _, v := range c.versions[name] {
+		versions = append(versions, &releaseVersion{version: v})
+	}
+	return &release{versions: versions}, nil
+}
+
+func TestMergeReleases(t *testing.T) {
+	a := &release{versions: []*releaseVersion{
+		{version: "1.0.0", platforms: []string{"linux_amd64"}},
+		{version: "1.1.0", platforms: []string{"linux_amd64"}},
+	}}
+	b := &release{source: "https://github.com/foo/bar", versions: []*releaseVersion{
+		{version: "1.0.0", platforms: []string{"darwin_arm64"}},
+		{version: "1.1.0"},
+		{version: "1.2.0"},
+	}}
+	merged := mergeReleases(a, b)
+	require.Equal(t, "https://github.com/foo/bar", merged.getSource())
+	require.Equal(t, []string{"1.0.0", "1.1.0", "1.2.0"}, versionNames(merged))
+	require.Equal(t, []string{"linux_amd64", "darwin_arm64"}, merged.versions[0].platforms)
+	require.Nil(t, merged.versions[1].platforms)
+}
+
+func TestRegistries(t *testing.T) {
+	regs := NewRegistries(afero.NewMemMapFs(), httpclient.NewClient(httpclient.DefaultOptions()), nil)
+	reg, err := regs.Get(TerraformRegistryHost)
+	require.NoError(t, err)
+	other, err := regs.Get(TerraformRegistryHost)
+	require.NoError(t, err)
+	require.Equal(t, reg, other)
+	require.Equal(t, 1, regs.registries.Len())
+
+	// versions are shared between default hosts by the fully qualified address
+	requests := 0
+	upstream := countingRegistry{
+		versions: map[string][]string{
+			"hashicorp/aws":                       {"3.59.0"},
+			"registry.terraform.io/hashicorp/aws": {"3.60.0"},
+		},
+		requests: &requests,
+	}
+	terraform := cachedRegistry{registry: upstream, defaultHost: TerraformRegistryHost, cache: regs.cache}
+	tofu := cachedRegistry{registry: upstream, defaultHost: OpenTofuRegistryHost, cache: regs.cache}
+	rel, err := terraform.getVersions("hashicorp/aws")
+	require.NoError(t, err)
+	require.Equal(t, []string{"3.59.0"}, versionNames(rel))
+	rel, err = tofu.getVersions("registry.terraform.io/hashicorp/aws")
+	require.NoError(t, err)
+	require.Equal(t, []string{"3.59.0"}, versionNames(rel))
+	require.Equal(t, 1, requests)
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/xenitab/tf-provider-latest/internal/cliconfig"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+	"github.com/xenitab/tf-provider-latest/internal/memo"
+)
+
+// NetworkMirrorRegistry resolves versions using the provider network mirror protocol.
+type NetworkMirrorRegistry struct {
+	client      *httpclient.Client
+	cliConfig   *cliconfig.Config
+	baseURL     *url.URL
+	defaultHost string
+	cache       *memo.Cache[*release]
+}
+
+func NewNetworkMirrorRegistry(
+	client *httpclient.Client, cliConfig *cliconfig.Config, mirrorURL, defaultHost string,
+) (NetworkMirrorRegistry, error) {
+	baseURL, err := url.Parse(mirrorURL)
+	if err != nil {
+		return NetworkMirrorRegistry{}, fmt.Errorf("invalid network mirror url %q: %w", mirrorURL, err)
+	}
+	if baseURL.Scheme != "https" {
+		return NetworkMirrorRegistry{}, fmt.Errorf("network mirror url %q must use https", mirrorURL)
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
+
+	return NetworkMirrorRegistry{
+		client:      client,
+		cliConfig:   cliConfig,
+		baseURL:     baseURL,
+		defaultHost: defaultHost,
+		cache:       memo.NewCache[*release](),
+	}, nil
+}
+
+type mirrorIndex struct {
+	Versions map[string]interface{} `json:"versions"`
+}
+
+func (n NetworkMirrorRegistry) getVersions(name string) (*release, error) {
+	addr, err := parseAddress(name, n.defaultHost)
+	if err != nil {
+		return nil, err
+	}
+	return n.cache.Get(addr.String(), func() (*release, error) {
+		return n.fetchVersions(addr)
+	})
+}
+
+func (n NetworkMirrorRegistry) fetchVersions(addr address) (*release, error) {
+	u, err := n.baseURL.Parse(fmt.Sprintf("%s/index.json", addr.String()))
+	if err != nil {
+		return nil, err
+	}
+	r, err := get(n.client, n.cliConfig, u)
+	if err != nil {
+		return nil, fmt.Errorf("network mirror request for %q failed: %w", addr.String(), err)
+	}
+	defer r.Body.Close()
+	index := &mirrorIndex{}
+	err = json.NewDecoder(r.Body).Decode(index)
+	if err != nil {
+		return nil, err
+	}
+
+	// the mirror protocol does not include the provider source repository and platforms are only listed per version
+	rel := &release{versions: []*releaseVersion{}}
+	for v := range index.Versions {
+		rel.versions = append(rel.versions, &releaseVersion{version: v})
+	}
+	return rel, nil
+}
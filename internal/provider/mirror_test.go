@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkMirrorRegistry(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/providers/registry.terraform.io/hashicorp/azurerm/index.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions": {"2.35.0": {}, "2.53.0": {}, "2.9.0": {}, "3.0.0-beta1": {}}}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+
+	reg, err := NewNetworkMirrorRegistry(newTestClient(srv), nil, srv.URL+"/providers", TerraformRegistryHost)
+	require.NoError(t, err)
+	rel, err := reg.getVersions("hashicorp/azurerm")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"2.35.0", "2.53.0", "2.9.0", "3.0.0-beta1"}, versionNames(rel))
+
+	_, err = reg.getVersions("hashicorp/aws")
+	require.Error(t, err)
+}
+
+func TestNetworkMirrorRegistryHTTP(t *testing.T) {
+	_, err := NewNetworkMirrorRegistry(nil, nil, "http://mirror.example.com/", TerraformRegistryHost)
+	require.Error(t, err)
+}
@@ -4,17 +4,21 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/minamijoyo/tfupdate/tfupdate"
 	"github.com/spf13/afero"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/xenitab/tf-provider-latest/internal/annotation"
+	"github.com/xenitab/tf-provider-latest/internal/constraint"
 	"github.com/xenitab/tf-provider-latest/internal/result"
 	"github.com/xenitab/tf-provider-latest/internal/util"
 )
 
-func Update(fs afero.Fs, path string, reg Registry, providerSelector *[]string) (*result.Result, error) {
+// Lookups returns a lookup of the versions of every provider in the file which would be updated, keyed by source so
+// that the same provider in multiple files is only looked up once. Errors are returned when the file is updated.
+func Lookups(fs afero.Fs, path string, reg Registry, providerSelector *[]string) (map[string]func(), error) {
 	hclFile, _, annos, err := util.ReadHCLFile(fs, path)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read providers for %s: %w", path, err)
@@ -24,32 +28,80 @@ func Update(fs afero.Fs, path string, reg Registry, providerSelector *[]string)
 		return nil, fmt.Errorf("unable to parse required providers for %s: %w", path, err)
 	}
 
-	selector := map[string]string{}
-	if providerSelector != nil {
-		for _, s := range *providerSelector {
-			selector[s] = s
+	lookups := map[string]func(){}
+	for _, p := range pp {
+		if p.warning != "" || ignoreReason(p, annos, providerSelector) != "" {
+			continue
+		}
+		source := p.source
+		lookups[source] = func() {
+			//nolint:errcheck // the error is returned again when the file is updated
+			reg.getVersions(source)
 		}
 	}
-	res := result.NewResult("Provider")
+	return lookups, nil
+}
+
+// Update updates the providers in the file to the version selected by the strategy which is built for all of the platforms.
+func Update(
+	fs afero.Fs, path string, reg Registry, providerSelector *[]string, strategy string, platforms []string,
+) (*result.Result, error) {
+	hclFile, _, annos, err := util.ReadHCLFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read providers for %s: %w", path, err)
+	}
+	pp, err := parseRequiredProviders(hclFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse required providers for %s: %w", path, err)
+	}
+
+	res := result.NewResult(result.TitleProvider)
 	for _, p := range pp {
-		if _, ok := selector[p.source]; providerSelector != nil && !ok {
-			res.Ignored = append(res.Ignored, &result.Ignore{Name: p.source, Path: path})
+		if reason := ignoreReason(p, annos, providerSelector); reason != "" {
+			res.Ignored = append(res.Ignored, &result.Ignore{Name: p.source, Path: path, Reason: reason})
 			continue
 		}
-		if annotation.ShouldSkipBlock(annos, p.blockRange) {
-			res.Ignored = append(res.Ignored, &result.Ignore{Name: p.source, Path: path})
+		if p.warning != "" {
+			res.Warnings = append(res.Warnings, &result.Warning{Name: p.source, Path: path, Message: p.warning})
 			continue
 		}
 
-		latestVersion, err := reg.getLatestVersion(p.source)
+		rel, err := reg.getVersions(p.source)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get versions of provider %s - %s: %w", path, p.source, err)
+		}
+		latestVersion, skipped, err := selectVersion(p.source, rel.versions, p.version, strategy, platforms)
+		var warning *versionWarning
+		if errors.As(err, &warning) {
+			res.Warnings = append(res.Warnings, &result.Warning{Name: p.source, Path: path, Message: warning.message})
+			continue
+		}
 		if err != nil {
 			return nil, fmt.Errorf("unable to get latest version of provider %s - %s: %w", path, p.source, err)
 		}
-		if latestVersion == p.version {
+		res.Skipped = append(res.Skipped, skipped...)
+		if latestVersion == "" {
+			res.Current = append(res.Current, &result.Current{Name: p.source, Path: path, Version: p.version})
+			continue
+		}
+		newVersion, ok, err := updatedConstraint(p.version, latestVersion)
+		if errors.Is(err, errExcludedVersion) {
+			res.Warnings = append(res.Warnings, &result.Warning{
+				Name:    p.source,
+				Path:    path,
+				Message: fmt.Sprintf("latest version %s is excluded by the version constraint", latestVersion),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to compare version of provider %s - %s: %w", path, p.source, err)
+		}
+		if !ok {
+			res.Current = append(res.Current, &result.Current{Name: p.source, Path: path, Version: p.version})
 			continue
 		}
 
-		o, err := tfupdate.NewOption("provider", p.name, latestVersion, false, []string{})
+		o, err := tfupdate.NewOption("provider", p.name, newVersion, false, []string{})
 		if err != nil {
 			return nil, fmt.Errorf("unable to get new option of provider %s - %s: %w", path, p.source, err)
 		}
@@ -57,17 +109,85 @@ func Update(fs afero.Fs, path string, reg Registry, providerSelector *[]string)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get update file or dir of provider %s - %s: %w", path, p.source, err)
 		}
-		res.Updated = append(res.Updated, &result.Update{Name: p.source, OldVersion: p.version, NewVersion: latestVersion})
+		res.Updated = append(res.Updated, &result.Update{
+			Name:       p.source,
+			OldVersion: p.version,
+			NewVersion: newVersion,
+			ReleaseURL: releaseURL(rel.getSource(), latestVersion),
+			Occurrences: []*result.Occurrence{
+				{
+					Path:    path,
+					Address: fmt.Sprintf("required_providers.%s", p.name),
+					Range:   p.blockRange,
+				},
+			},
+		})
 	}
 
 	return res, nil
 }
 
+var errExcludedVersion = errors.New("latest version is excluded by the version constraint")
+
+// updatedConstraint returns the constraint that allows the latest version and true if it differs from the current constraint.
+func updatedConstraint(current, latest string) (string, bool, error) {
+	if current == "" {
+		return latest, true, nil
+	}
+	cc, err := constraint.Parse(current)
+	if err != nil {
+		return "", false, err
+	}
+	v, err := semver.NewVersion(latest)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid latest version %q: %w", latest, err)
+	}
+	if cc.Check(v) {
+		return current, false, nil
+	}
+	// a latest version below the constraint, such as a stable version below a prerelease pin, is never a downgrade
+	if base := cc.Base(); base != nil && v.LessThan(base) {
+		return current, false, nil
+	}
+	// an explicitly excluded version is never pinned by the fallback below
+	if cc.Excludes(v) {
+		return "", false, errExcludedVersion
+	}
+	bumped := cc.Bump(v)
+	// fall back to pinning the latest version if the constraint can not be shifted to include it
+	if !bumped.Check(v) {
+		return latest, true, nil
+	}
+	return bumped.String(), true, nil
+}
+
+// ignoreReason returns why the provider is ignored, or an empty string if it should be updated.
+func ignoreReason(p *provider, annos []*annotation.Annotation, providerSelector *[]string) string {
+	if providerSelector != nil && !contains(*providerSelector, p.source) {
+		return result.IgnoreReasonSelector
+	}
+	if annotation.ShouldSkipBlock(annos, p.blockRange) {
+		return result.IgnoreReasonAnnotation
+	}
+	return ""
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 type provider struct {
 	name       string
 	source     string
 	version    string
 	blockRange hcl.Range
+	// warning is set when the entry can not be updated
+	warning string
 }
 
 func parseRequiredProviders(file *hcl.File) ([]*provider, error) {
@@ -125,41 +245,85 @@ func parseRequiredProviders(file *hcl.File) ([]*provider, error) {
 	return pp, nil
 }
 
+// parseProvider parses an entry in either the object or the legacy string syntax. Entries which can not be updated
+// are returned with a warning instead of an error so that the rest of the file is still updated.
 func parseProvider(name string, attr *hcl.Attribute) (*provider, error) {
-	keyValuePairs, diags := hcl.ExprMap(attr.Expr)
-	if diags.HasErrors() {
-		return nil, errors.New(diags.Error())
-	}
-
 	p := &provider{
 		name:       name,
 		blockRange: attr.Range,
 	}
+	err := parseProviderExpr(p, attr.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the source defaults to the hashicorp namespace when not set, just like in Terraform
+	if p.source == "" {
+		p.source = fmt.Sprintf("%s/%s", defaultNamespace, name)
+	}
+	if p.warning == "" && p.version == "" {
+		p.warning = "missing version constraint"
+	}
+	return p, nil
+}
+
+func parseProviderExpr(p *provider, expr hcl.Expression) error {
+	// legacy syntax where the value is only the version constraint
+	if value, diags := expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String {
+		p.version = value.AsString()
+		return nil
+	}
+
+	keyValuePairs, diags := hcl.ExprMap(expr)
+	if diags.HasErrors() {
+		p.warning = "unsupported required_providers entry, expected an object or a version string"
+		return nil
+	}
 	//nolint:gocritic // ignore for now
 	for _, kvp := range keyValuePairs {
-		key, diags := kvp.Key.Value(nil)
-		if diags.HasErrors() {
-			return nil, errors.New(diags.Error())
-		}
-
-		if key.Type() != cty.String {
-			return nil, errors.New("invalid key type")
+		key := hcl.ExprAsKeyword(kvp.Key)
+		if key == "" {
+			value, diags := kvp.Key.Value(nil)
+			if diags.HasErrors() || value.Type() != cty.String || value.IsNull() {
+				p.warning = "invalid key in required_providers entry"
+				continue
+			}
+			key = value.AsString()
 		}
 
-		switch key.AsString() {
+		// configuration_aliases contains references to provider configurations which can not be evaluated and
+		// does not need to be, so only the keys which are used are evaluated
+		switch key {
 		case "version":
-			version, diags := kvp.Value.Value(nil)
-			if diags.HasErrors() {
-				return nil, errors.New(diags.Error())
+			version, warning := stringValue(kvp.Value, "version constraint")
+			if warning != "" {
+				p.warning = warning
+				continue
 			}
-			p.version = version.AsString()
+			p.version = version
 		case "source":
-			source, diags := kvp.Value.Value(nil)
-			if diags.HasErrors() {
-				return nil, errors.New(diags.Error())
+			source, warning := stringValue(kvp.Value, "source")
+			if warning != "" {
+				p.warning = warning
+				continue
 			}
-			p.source = source.AsString()
+			p.source = source
 		}
 	}
-	return p, nil
+	return nil
+}
+
+// stringValue returns the value of a static string expression or a warning explaining why it can not be used.
+func stringValue(expr hcl.Expression, name string) (value, warning string) {
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return "", fmt.Sprintf("%s is not a static value", name)
+	}
+	if v.IsNull() {
+		return "", ""
+	}
+	if !v.IsKnown() || v.Type() != cty.String {
+		return "", fmt.Sprintf("%s is not a string", name)
+	}
+	return v.AsString(), ""
 }
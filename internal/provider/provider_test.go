@@ -5,8 +5,12 @@ import (
 	"os"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
+
+	"github.com/xenitab/tf-provider-latest/internal/result"
 )
 
 func createFs(content string) (afero.Fs, error) {
@@ -56,11 +60,15 @@ func TestProviderUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			fs, err := createFs(tt.input)
 			require.Nil(t, err)
-			res, err := Update(fs, "/tmp/terraform/main.tf", r, nil)
+			res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
 			require.Nil(t, err)
 			require.NotEmpty(t, res.Updated, "result list can not be empty")
 			require.Equal(t, "hashicorp/azurerm", res.Updated[0].Name)
 			require.Equal(t, "2.53.0", res.Updated[0].NewVersion)
+			require.Len(t, res.Updated[0].Occurrences, 1)
+			require.Equal(t, "/tmp/terraform/main.tf", res.Updated[0].Occurrences[0].Path)
+			require.Equal(t, "required_providers.azurerm", res.Updated[0].Occurrences[0].Address)
+			require.Equal(t, 6, res.Updated[0].Occurrences[0].Range.Start.Line)
 
 			file, err := fs.Open("/tmp/terraform/main.tf")
 			require.Nil(t, err)
@@ -71,13 +79,153 @@ func TestProviderUpdate(t *testing.T) {
 	}
 }
 
+func TestProviderReadOnlyBase(t *testing.T) {
+	base, err := createFs(basicTerraform)
+	require.Nil(t, err)
+	fs := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), afero.NewMemMapFs())
+	r := FakeRegistry{
+		providers: map[string][]string{
+			"hashicorp/azurerm": {"2.53.0"},
+		},
+	}
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
+	require.Nil(t, err)
+	require.NotEmpty(t, res.Updated)
+
+	file, err := base.Open("/tmp/terraform/main.tf")
+	require.Nil(t, err)
+	d, err := io.ReadAll(file)
+	require.Nil(t, err)
+	require.Equal(t, basicTerraform, string(d))
+}
+
+func TestProviderStrategy(t *testing.T) {
+	r := FakeRegistry{
+		providers: map[string][]string{
+			"hashicorp/azurerm": {"2.35.0", "2.35.7", "2.99.0", "3.4.1", "4.0.0-beta1"},
+		},
+	}
+	tests := []struct {
+		strategy string
+		expected string
+	}{
+		{strategy: StrategyLatest, expected: "3.4.1"},
+		{strategy: StrategyMinor, expected: "2.99.0"},
+		{strategy: StrategyPatch, expected: "2.35.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			fs, err := createFs(basicTerraform)
+			require.Nil(t, err)
+			res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, tt.strategy, nil)
+			require.Nil(t, err)
+			require.Len(t, res.Updated, 1)
+			require.Equal(t, tt.expected, res.Updated[0].NewVersion)
+		})
+	}
+}
+
+func TestProviderStrategyNoVersion(t *testing.T) {
+	fs, err := createFs(basicTerraform)
+	require.Nil(t, err)
+	r := FakeRegistry{
+		providers: map[string][]string{
+			"hashicorp/azurerm": {"2.36.0", "3.4.1"},
+		},
+	}
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyPatch, nil)
+	require.Nil(t, err)
+	require.Empty(t, res.Updated)
+	require.Len(t, res.Warnings, 1)
+	require.Equal(t, "no stable version matches the patch strategy", res.Warnings[0].Message)
+}
+
+func TestProviderLegacySyntax(t *testing.T) {
+	fs, err := createFs(legacyTerraform)
+	require.Nil(t, err)
+	r := FakeRegistry{
+		providers: map[string][]string{
+			"hashicorp/aws":     {"4.2.0"},
+			"hashicorp/azurerm": {"2.53.0"},
+		},
+	}
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
+	require.Nil(t, err)
+	require.Len(t, res.Updated, 2)
+	names := []string{res.Updated[0].Name, res.Updated[1].Name}
+	require.ElementsMatch(t, []string{"hashicorp/aws", "hashicorp/azurerm"}, names)
+	require.Len(t, res.Warnings, 2)
+	messages := map[string]string{}
+	for _, w := range res.Warnings {
+		messages[w.Name] = w.Message
+	}
+	require.Equal(t, "missing version constraint", messages["hashicorp/random"])
+	require.Equal(t, "version constraint is not a static value", messages["hashicorp/null"])
+
+	file, err := fs.Open("/tmp/terraform/main.tf")
+	require.Nil(t, err)
+	d, err := io.ReadAll(file)
+	require.Nil(t, err)
+	require.Equal(t, legacyTerraformExpected, string(d))
+}
+
+func TestProviderExcludedVersion(t *testing.T) {
+	fs, err := createFs(excludedVersionTerraform)
+	require.Nil(t, err)
+	r := FakeRegistry{
+		providers: map[string][]string{
+			"hashicorp/aws": {"3.59.0"},
+		},
+	}
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
+	require.Nil(t, err)
+	require.Empty(t, res.Updated)
+	require.Len(t, res.Warnings, 1)
+	require.Equal(t, "latest version 3.59.0 is excluded by the version constraint", res.Warnings[0].Message)
+}
+
+func TestProviderConfigurationAliases(t *testing.T) {
+	fs, err := createFs(configurationAliasesTerraform)
+	require.Nil(t, err)
+	r := FakeRegistry{
+		providers: map[string][]string{
+			"hashicorp/aws":     {"4.0.0"},
+			"hashicorp/azurerm": {"3.0.0"},
+		},
+	}
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
+	require.Nil(t, err)
+	require.Len(t, res.Updated, 1)
+	require.Len(t, res.Warnings, 1)
+	require.Equal(t, "hashicorp/azurerm", res.Warnings[0].Name)
+	require.Equal(t, "version constraint is not a string", res.Warnings[0].Message)
+
+	file, err := fs.Open("/tmp/terraform/main.tf")
+	require.Nil(t, err)
+	d, err := io.ReadAll(file)
+	require.Nil(t, err)
+	require.Equal(t, configurationAliasesTerraformExpected, string(d))
+}
+
+func TestParseProviderQuotedKeys(t *testing.T) {
+	file, diags := hclsyntax.ParseConfig([]byte(`random = { "source" = "hashicorp/random", "version" = "3.0.0" }`), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	attrs, diags := file.Body.JustAttributes()
+	require.False(t, diags.HasErrors())
+	p, err := parseProvider("random", attrs["random"])
+	require.NoError(t, err)
+	require.Equal(t, "hashicorp/random", p.source)
+	require.Equal(t, "3.0.0", p.version)
+	require.Empty(t, p.warning)
+}
+
 func TestProviderEmptyRequired(t *testing.T) {
 	fs, err := createFs(noRequiredProviders)
 	require.Nil(t, err)
 	r := FakeRegistry{
 		providers: map[string][]string{},
 	}
-	_, err = Update(fs, "/tmp/terraform/main.tf", r, nil)
+	_, err = Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
 	require.Nil(t, err)
 }
 
@@ -89,10 +237,11 @@ func TestProviderIgnore(t *testing.T) {
 			"hashicorp/azurerm": {"2.53.0"},
 		},
 	}
-	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil)
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
 	require.Nil(t, err)
 	require.Empty(t, res.Updated)
 	require.NotEmpty(t, res.Ignored)
+	require.Equal(t, result.IgnoreReasonAnnotation, res.Ignored[0].Reason)
 }
 
 func TestProviderFalsePositive(t *testing.T) {
@@ -103,7 +252,7 @@ func TestProviderFalsePositive(t *testing.T) {
 			"hashicorp/azurerm": {"2.53.0"},
 		},
 	}
-	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil)
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, nil, StrategyLatest, nil)
 	require.Nil(t, err)
 	require.NotEmpty(t, res.Updated)
 	require.Empty(t, res.Ignored)
@@ -119,11 +268,13 @@ func TestProviderSelector(t *testing.T) {
 		},
 	}
 	providerSelector := []string{"hashicorp/azurerm"}
-	res, err := Update(fs, "/tmp/terraform/main.tf", r, &providerSelector)
+	res, err := Update(fs, "/tmp/terraform/main.tf", r, &providerSelector, StrategyLatest, nil)
 
 	require.Nil(t, err)
 	require.Len(t, res.Updated, 1)
 	require.Len(t, res.Ignored, 1)
+	require.Equal(t, result.IgnoreReasonSelector, res.Ignored[0].Reason)
+	require.Empty(t, res.Current)
 
 	file, err := fs.Open("/tmp/terraform/main.tf")
 	require.Nil(t, err)
@@ -132,6 +283,76 @@ func TestProviderSelector(t *testing.T) {
 	require.Equal(t, providerSelectorExpected, string(d))
 }
 
+func TestProviderLookups(t *testing.T) {
+	fs, err := createFs(providerSelector)
+	require.Nil(t, err)
+	requests := 0
+	r := countingRegistry{
+		versions: map[string][]string{
+			"hashicorp/azurerm": {"2.77.0"},
+			"hashicorp/aws":     {"3.59.0"},
+		},
+		requests: &requests,
+	}
+	providerSelector := []string{"hashicorp/azurerm"}
+	lookups, err := Lookups(fs, "/tmp/terraform/main.tf", r, &providerSelector)
+	require.Nil(t, err)
+	require.Len(t, lookups, 1)
+	require.Contains(t, lookups, "hashicorp/azurerm")
+	lookups["hashicorp/azurerm"]()
+	require.Equal(t, 1, requests)
+
+	fs, err = createFs(ignoreTerraform)
+	require.Nil(t, err)
+	lookups, err = Lookups(fs, "/tmp/terraform/main.tf", r, nil)
+	require.Nil(t, err)
+	require.Empty(t, lookups)
+}
+
+func TestUpdatedConstraint(t *testing.T) {
+	tests := []struct {
+		current  string
+		latest   string
+		expected string
+		updated  bool
+	}{
+		{current: "2.35.0", latest: "2.53.0", expected: "2.53.0", updated: true},
+		{current: "2.53.0", latest: "2.53.0", expected: "2.53.0", updated: false},
+		{current: "~> 2.53", latest: "2.99.1", expected: "~> 2.53", updated: false},
+		{current: "~> 2.53", latest: "3.4.1", expected: "~> 3.4", updated: true},
+		{current: "~>2.53.0", latest: "2.54.2", expected: "~> 2.54.2", updated: true},
+		{current: "= 1.2", latest: "1.3.0", expected: "= 1.3", updated: true},
+		{current: ">= 2.0, < 3.0", latest: "3.4.1", expected: ">= 3.0, < 4.0", updated: true},
+		{current: ">= 2.1, < 2.3", latest: "2.5.0", expected: ">= 2.5, < 2.7", updated: true},
+		{current: ">= 2.0", latest: "3.4.1", expected: ">= 2.0", updated: false},
+		{current: "< 3.0", latest: "3.4.1", expected: "< 3.5", updated: true},
+		{current: "~> 2.0, != 2.1.0", latest: "3.1.0", expected: "~> 3.1, != 2.1.0", updated: true},
+		{current: "", latest: "3.1.0", expected: "3.1.0", updated: true},
+		{current: "~> 3.6", latest: "3.5.2", expected: "~> 3.6", updated: false},
+		{current: "= 3.6.0", latest: "3.5.2", expected: "= 3.6.0", updated: false},
+		{current: ">= 3.6, < 4.0", latest: "3.5.2", expected: ">= 3.6, < 4.0", updated: false},
+		{current: "4.0.0-beta1", latest: "3.9.0", expected: "4.0.0-beta1", updated: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			version, updated, err := updatedConstraint(tt.current, tt.latest)
+			require.NoError(t, err)
+			require.Equal(t, tt.updated, updated)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestUpdatedConstraintExcluded(t *testing.T) {
+	for _, current := range []string{">= 2.0, != 3.4.1", "!= 3.4.1"} {
+		t.Run(current, func(t *testing.T) {
+			_, updated, err := updatedConstraint(current, "3.4.1")
+			require.ErrorIs(t, err, errExcludedVersion)
+			require.False(t, updated)
+		})
+	}
+}
+
 const basicTerraform = `
 terraform {
   required_version = "0.13.5"
@@ -272,3 +493,82 @@ terraform {
 
 provider "azurerm" {}
 `
+
+const legacyTerraform = `
+terraform {
+  required_providers {
+    aws = "~> 3.0"
+    azurerm = {
+      version = "2.35.0"
+    }
+    random = {
+      source = "hashicorp/random"
+    }
+    null = {
+      source  = "hashicorp/null"
+      version = var.null_version
+    }
+  }
+}
+`
+
+const legacyTerraformExpected = `
+terraform {
+  required_providers {
+    aws = "~> 4.2"
+    azurerm = {
+      version = "2.53.0"
+    }
+    random = {
+      source = "hashicorp/random"
+    }
+    null = {
+      source  = "hashicorp/null"
+      version = var.null_version
+    }
+  }
+}
+`
+
+const configurationAliasesTerraform = `
+terraform {
+  required_providers {
+    aws = {
+      source                = "hashicorp/aws"
+      version               = "3.58.0"
+      configuration_aliases = [aws.east, aws.west]
+    }
+    azurerm = {
+      source  = "hashicorp/azurerm"
+      version = ["2.35.0"]
+    }
+  }
+}
+`
+
+const configurationAliasesTerraformExpected = `
+terraform {
+  required_providers {
+    aws = {
+      source                = "hashicorp/aws"
+      version               = "4.0.0"
+      configuration_aliases = [aws.east, aws.west]
+    }
+    azurerm = {
+      source  = "hashicorp/azurerm"
+      version = ["2.35.0"]
+    }
+  }
+}
+`
+
+const excludedVersionTerraform = `
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 2.0, != 3.59.0"
+    }
+  }
+}
+`
@@ -5,67 +5,242 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"time"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/xenitab/tf-provider-latest/internal/cliconfig"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+	"github.com/xenitab/tf-provider-latest/internal/memo"
 )
 
 type Registry interface {
-	getLatestVersion(name string) (string, error)
+	getVersions(name string) (*release, error)
+}
+
+// release contains all published versions of a provider and its source repository if known.
+type release struct {
+	versions []*releaseVersion
+	// lookupSource returns the source repository, it is only called for updated providers as it can send a request
+	lookupSource func() string
+	sourceOnce   sync.Once
+	source       string
+}
+
+// getSource returns the source repository of the provider, or an empty string if it is not known.
+func (r *release) getSource() string {
+	r.sourceOnce.Do(func() {
+		if r.lookupSource != nil {
+			r.source = r.lookupSource()
+		}
+	})
+	return r.source
+}
+
+// releaseVersion is a published version with the platforms, formatted as os_arch, it was built for.
+// Platforms are nil when the registry does not know them.
+type releaseVersion struct {
+	version   string
+	platforms []string
 }
 
+const providersServiceID = "providers.v1"
+
 type HashicorpRegistry struct {
-	cache map[string]string
+	client      *httpclient.Client
+	cliConfig   *cliconfig.Config
+	defaultHost string
+	services    *memo.Cache[*url.URL]
+	cache       *memo.Cache[*release]
 }
 
-func NewHashicorpRegistry() HashicorpRegistry {
+// NewHashicorpRegistry returns a registry which resolves sources without hostname against defaultHost.
+func NewHashicorpRegistry(client *httpclient.Client, cliConfig *cliconfig.Config, defaultHost string) HashicorpRegistry {
 	return HashicorpRegistry{
-		cache: map[string]string{},
+		client:      client,
+		cliConfig:   cliConfig,
+		defaultHost: defaultHost,
+		services:    memo.NewCache[*url.URL](),
+		cache:       memo.NewCache[*release](),
+	}
+}
+
+// OpenTofuRegistry is a registry which resolves sources without hostname against the OpenTofu registry.
+type OpenTofuRegistry struct {
+	HashicorpRegistry
+}
+
+func NewOpenTofuRegistry(client *httpclient.Client, cliConfig *cliconfig.Config) OpenTofuRegistry {
+	return OpenTofuRegistry{
+		HashicorpRegistry: NewHashicorpRegistry(client, cliConfig, OpenTofuRegistryHost),
 	}
 }
 
 type versionRoot struct {
-	Version string `json:"version"`
+	Source string `json:"source"`
+}
+
+type versionsRoot struct {
+	Versions []*versionsEntry `json:"versions"`
 }
 
-func (h HashicorpRegistry) getLatestVersion(name string) (string, error) {
+type versionsEntry struct {
+	Version   string              `json:"version"`
+	Platforms []*versionsPlatform `json:"platforms"`
+}
+
+type versionsPlatform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+func (h HashicorpRegistry) getVersions(name string) (*release, error) {
 	if name == "" {
-		return "", errors.New("name cannot be empty")
+		return nil, errors.New("name cannot be empty")
+	}
+	addr, err := parseAddress(name, h.defaultHost)
+	if err != nil {
+		return nil, err
 	}
 
-	// no need to lookup if latest version is cached
-	if version, ok := h.cache[name]; ok {
-		return version, nil
+	// no need to lookup if versions are cached
+	return h.cache.Get(addr.String(), func() (*release, error) {
+		return h.fetchVersions(addr)
+	})
+}
+
+func (h HashicorpRegistry) fetchVersions(addr address) (*release, error) {
+	providersURL, err := h.discoverProviders(addr.hostname)
+	if err != nil {
+		return nil, err
 	}
+	u, err := providersURL.Parse(fmt.Sprintf("%s/%s/versions", addr.namespace, addr.name))
+	if err != nil {
+		return nil, err
+	}
+	r, err := get(h.client, h.cliConfig, u)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	vr := &versionsRoot{}
+	err = json.NewDecoder(r.Body).Decode(vr)
+	if err != nil {
+		return nil, err
+	}
+	if len(vr.Versions) == 0 {
+		return nil, fmt.Errorf("versions for %q cannot be empty", addr.String())
+	}
+
+	rel := &release{
+		versions: []*releaseVersion{},
+		lookupSource: func() string {
+			return h.getSource(providersURL, addr)
+		},
+	}
+	for _, v := range vr.Versions {
+		// registries which do not list platforms are treated as unknown instead of built for nothing
+		var platforms []string
+		if v.Platforms != nil {
+			platforms = []string{}
+		}
+		for _, p := range v.Platforms {
+			platforms = append(platforms, fmt.Sprintf("%s_%s", p.OS, p.Arch))
+		}
+		rel.versions = append(rel.versions, &releaseVersion{version: v.Version, platforms: platforms})
+	}
+	return rel, nil
+}
 
-	url := fmt.Sprintf("https://registry.terraform.io/v1/providers/%s", name)
-	client := http.Client{Timeout: 10 * time.Second}
-	r, err := client.Get(url)
+// getSource returns the source repository of the provider, which is not part of the provider registry protocol
+// so an empty string is returned if the registry does not support it.
+func (h HashicorpRegistry) getSource(providersURL *url.URL, addr address) string {
+	u, err := providersURL.Parse(fmt.Sprintf("%s/%s", addr.namespace, addr.name))
+	if err != nil {
+		return ""
+	}
+	r, err := get(h.client, h.cliConfig, u)
 	if err != nil {
-		return "", err
+		return ""
 	}
 	defer r.Body.Close()
 	vr := &versionRoot{}
 	err = json.NewDecoder(r.Body).Decode(vr)
 	if err != nil {
-		return "", err
+		return ""
+	}
+	return vr.Source
+}
+
+// discoverProviders returns the base URL of the provider registry API on the host using Terraform's remote service discovery.
+func (h HashicorpRegistry) discoverProviders(hostname string) (*url.URL, error) {
+	return h.services.Get(hostname, func() (*url.URL, error) {
+		return h.fetchProvidersURL(hostname)
+	})
+}
+
+func (h HashicorpRegistry) fetchProvidersURL(hostname string) (*url.URL, error) {
+	discoveryURL := &url.URL{Scheme: "https", Host: hostname, Path: "/.well-known/terraform.json"}
+	r, err := get(h.client, h.cliConfig, discoveryURL)
+	if err != nil {
+		return nil, fmt.Errorf("service discovery for %q failed: %w", hostname, err)
+	}
+	defer r.Body.Close()
+	services := map[string]interface{}{}
+	err = json.NewDecoder(r.Body).Decode(&services)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service discovery document for %q: %w", hostname, err)
 	}
-	if vr.Version == "" {
-		return "", fmt.Errorf("latest version for %q cannot be empty", name)
+	providers, ok := services[providersServiceID].(string)
+	if !ok {
+		return nil, fmt.Errorf("host %q does not provide a provider registry", hostname)
 	}
 
-	v := vr.Version
-	h.cache[name] = v
-	return v, nil
+	// the service URL can be relative to the discovery document
+	u, err := discoveryURL.Parse(providers)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u, nil
+}
+
+// get sends a GET request with the API token of the requested host if there is one, non 2xx responses are returned as errors.
+func get(client *httpclient.Client, cliConfig *cliconfig.Config, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if token := cliConfig.Token(u.Host); token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	return client.Do(req)
 }
 
 type FakeRegistry struct {
 	providers map[string][]string
 }
 
-func (f FakeRegistry) getLatestVersion(name string) (string, error) {
+func (f FakeRegistry) getVersions(name string) (*release, error) {
 	versions, ok := f.providers[name]
 	if !ok {
-		return "", fmt.Errorf("provider %q not found", name)
+		return nil, fmt.Errorf("provider %q not found", name)
 	}
 
-	return versions[0], nil
+	rel := &release{versions: []*releaseVersion{}}
+	for _, v := range versions {
+		rel.versions = append(rel.versions, &releaseVersion{version: v})
+	}
+	return rel, nil
+}
+
+// releaseURL returns the release page of the version for GitHub sources, other sources are returned as is.
+func releaseURL(source, version string) string {
+	u, err := url.Parse(source)
+	if err != nil || u.Host != "github.com" {
+		return source
+	}
+	return fmt.Sprintf("%s/releases/tag/v%s", strings.TrimSuffix(source, "/"), version)
 }
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xenitab/tf-provider-latest/internal/cliconfig"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+)
+
+func TestReleaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "github",
+			source:   "https://github.com/hashicorp/terraform-provider-azurerm",
+			expected: "https://github.com/hashicorp/terraform-provider-azurerm/releases/tag/v2.53.0",
+		},
+		{
+			name:     "other",
+			source:   "https://gitlab.com/foo/terraform-provider-bar",
+			expected: "https://gitlab.com/foo/terraform-provider-bar",
+		},
+		{
+			name:     "empty",
+			source:   "",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, releaseURL(tt.source, "2.53.0"))
+		})
+	}
+}
+
+func versionNames(rel *release) []string {
+	names := []string{}
+	for _, v := range rel.versions {
+		names = append(names, v.version)
+	}
+	return names
+}
+
+func newTestClient(srv *httptest.Server) *httpclient.Client {
+	return httpclient.NewClient(httpclient.Options{Transport: srv.Client().Transport})
+}
+
+func newTestRegistryServer(t *testing.T, sourceRequests *int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/terraform.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"providers.v1": "/api/providers/v1/"}`)
+	})
+	mux.HandleFunc("/api/providers/v1/acme/internal", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(sourceRequests, 1)
+		fmt.Fprint(w, `{"version": "1.2.3", "source": "https://github.com/acme/terraform-provider-internal"}`)
+	})
+	mux.HandleFunc("/api/providers/v1/acme/internal/versions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions": [{"version": "1.2.0"}, {"version": "1.2.3", "platforms": [{"os": "linux", "arch": "amd64"}]}]}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHashicorpRegistryServiceDiscovery(t *testing.T) {
+	var sourceRequests int32
+	srv := newTestRegistryServer(t, &sourceRequests)
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	reg := NewHashicorpRegistry(newTestClient(srv), nil, TerraformRegistryHost)
+	rel, err := reg.getVersions(fmt.Sprintf("%s/acme/internal", host))
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.2.0", "1.2.3"}, versionNames(rel))
+	// the source is only requested when it is used
+	require.Equal(t, int32(0), atomic.LoadInt32(&sourceRequests))
+	require.Equal(t, "https://github.com/acme/terraform-provider-internal", rel.getSource())
+	require.Equal(t, "https://github.com/acme/terraform-provider-internal", rel.getSource())
+	require.Equal(t, int32(1), atomic.LoadInt32(&sourceRequests))
+	// versions without a platforms list are built for unknown platforms
+	require.Nil(t, rel.versions[0].platforms)
+	require.Equal(t, []string{"linux_amd64"}, rel.versions[1].platforms)
+
+	// default host is used for sources without hostname
+	reg.defaultHost = host
+	rel, err = reg.getVersions("acme/internal")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.2.0", "1.2.3"}, versionNames(rel))
+}
+
+func TestHashicorpRegistryNoProviders(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/terraform.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"modules.v1": "/v1/modules/"}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	reg := NewHashicorpRegistry(newTestClient(srv), nil, TerraformRegistryHost)
+	_, err := reg.getVersions(fmt.Sprintf("%s/acme/internal", host))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not provide a provider registry")
+}
+
+func TestHashicorpRegistryCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/terraform.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"providers.v1": "/v1/providers/"}`)
+	})
+	mux.HandleFunc("/v1/providers/acme/internal/versions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer foobar" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"versions": [{"version": "1.2.0"}, {"version": "1.2.3"}]}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	fs := afero.NewMemMapFs()
+	cliConfig := fmt.Sprintf("credentials %q {\n  token = \"foobar\"\n}\n", host)
+	err := afero.WriteFile(fs, "/tmp/terraformrc", []byte(cliConfig), os.FileMode(0600))
+	require.NoError(t, err)
+	t.Setenv("TF_CLI_CONFIG_FILE", "/tmp/terraformrc")
+	cfg, err := cliconfig.Load(fs)
+	require.NoError(t, err)
+
+	reg := NewHashicorpRegistry(newTestClient(srv), cfg, TerraformRegistryHost)
+	rel, err := reg.getVersions(fmt.Sprintf("%s/acme/internal", host))
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.2.0", "1.2.3"}, versionNames(rel))
+}
+
+func TestHashicorpRegistryWithoutSource(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/terraform.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"providers.v1": "/v1/providers/"}`)
+	})
+	mux.HandleFunc("/v1/providers/acme/internal/versions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"versions": [{"version": "1.2.3"}]}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	reg := NewHashicorpRegistry(newTestClient(srv), nil, TerraformRegistryHost)
+	rel, err := reg.getVersions(fmt.Sprintf("%s/acme/internal", host))
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.2.3"}, versionNames(rel))
+	require.Empty(t, rel.getSource())
+
+	_, err = reg.getVersions(fmt.Sprintf("%s/acme/missing", host))
+	require.Error(t, err)
+}
+
+func TestOpenTofuRegistryDefaultHost(t *testing.T) {
+	reg := NewOpenTofuRegistry(httpclient.NewClient(httpclient.DefaultOptions()), nil)
+	require.Equal(t, OpenTofuRegistryHost, reg.defaultHost)
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/xenitab/tf-provider-latest/internal/constraint"
+	"github.com/xenitab/tf-provider-latest/internal/result"
+)
+
+// Strategies used to select the version a provider is updated to.
+const (
+	StrategyLatest = "latest"
+	StrategyMinor  = "minor"
+	StrategyPatch  = "patch"
+)
+
+// selectVersion returns the highest stable version allowed by the strategy, where minor keeps the major version
+// and patch keeps the major and minor version of the current constraint. When platforms are set, versions which are
+// not built for all of them are skipped and returned with the reason. An empty version is returned when no allowed
+// version is at least the current constraint or all of them are skipped, so that a skipped version never causes a
+// downgrade.
+func selectVersion(
+	name string, versions []*releaseVersion, current, strategy string, platforms []string,
+) (string, []*result.Skip, error) {
+	platformsByVersion := map[string][]string{}
+	names := []string{}
+	for _, rv := range versions {
+		platformsByVersion[rv.version] = rv.platforms
+		names = append(names, rv.version)
+	}
+	vv := stableVersions(names)
+	if len(vv) == 0 {
+		return "", nil, errors.New("no stable versions found")
+	}
+
+	var base *semver.Version
+	if current != "" {
+		cc, err := constraint.Parse(current)
+		if err != nil {
+			return "", nil, err
+		}
+		base = cc.Base()
+	}
+
+	skipped := []*result.Skip{}
+	for _, v := range vv {
+		ok, err := allowedByStrategy(v, base, strategy)
+		if err != nil {
+			return "", nil, err
+		}
+		if !ok {
+			continue
+		}
+		if base != nil && v.LessThan(base) {
+			return "", skipped, nil
+		}
+		missing := missingPlatforms(platformsByVersion[v.Original()], platforms)
+		if len(missing) > 0 {
+			skipped = append(skipped, &result.Skip{
+				Name:    name,
+				Version: v.Original(),
+				Reason:  fmt.Sprintf("missing platforms %s", strings.Join(missing, ", ")),
+			})
+			continue
+		}
+		return v.Original(), skipped, nil
+	}
+	// the current version is kept when every allowed version is skipped
+	if len(skipped) > 0 {
+		return "", skipped, nil
+	}
+	return "", nil, &versionWarning{message: fmt.Sprintf("no stable version matches the %s strategy", strategy)}
+}
+
+// versionWarning is returned when no version can be selected for a single entry, it is reported as a warning instead
+// of failing the run.
+type versionWarning struct {
+	message string
+}
+
+func (w *versionWarning) Error() string {
+	return w.message
+}
+
+// missingPlatforms returns the required platforms which are not available, unknown platforms are assumed to be available.
+func missingPlatforms(available, required []string) []string {
+	missing := []string{}
+	if available == nil {
+		return missing
+	}
+	for _, r := range required {
+		found := false
+		for _, a := range available {
+			if a == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+func allowedByStrategy(v, base *semver.Version, strategy string) (bool, error) {
+	switch strategy {
+	case StrategyLatest, "":
+		return true, nil
+	case StrategyMinor:
+		return base == nil || v.Major() == base.Major(), nil
+	case StrategyPatch:
+		return base == nil || (v.Major() == base.Major() && v.Minor() == base.Minor()), nil
+	default:
+		return false, fmt.Errorf("unknown strategy %q", strategy)
+	}
+}
+
+// stableVersions returns the versions which are not a prerelease sorted from highest to lowest, invalid versions are skipped.
+func stableVersions(versions []string) []*semver.Version {
+	vv := []*semver.Version{}
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			continue
+		}
+		if v.Prerelease() != "" {
+			continue
+		}
+		vv = append(vv, v)
+	}
+	sort.Sort(sort.Reverse(semver.Collection(vv)))
+	return vv
+}
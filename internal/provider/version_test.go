@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newReleaseVersions(versions ...string) []*releaseVersion {
+	rvs := []*releaseVersion{}
+	for _, v := range versions {
+		rvs = append(rvs, &releaseVersion{version: v})
+	}
+	return rvs
+}
+
+func TestSelectVersion(t *testing.T) {
+	versions := newReleaseVersions("1.0.0", "1.10.0", "1.9.0", "1.9.3", "2.0.0-rc1", "foo")
+	tests := []struct {
+		current  string
+		strategy string
+		expected string
+	}{
+		{current: "1.9.0", strategy: StrategyLatest, expected: "1.10.0"},
+		{current: "", strategy: StrategyPatch, expected: "1.10.0"},
+		{current: "~> 1.9", strategy: StrategyPatch, expected: "1.9.3"},
+		{current: ">= 1.0, < 2.0", strategy: StrategyMinor, expected: "1.10.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"/"+tt.strategy, func(t *testing.T) {
+			v, skipped, err := selectVersion("foo/bar", versions, tt.current, tt.strategy, nil)
+			require.NoError(t, err)
+			require.Empty(t, skipped)
+			require.Equal(t, tt.expected, v)
+		})
+	}
+
+	_, _, err := selectVersion("foo/bar", newReleaseVersions("2.0.0-rc1"), "", StrategyLatest, nil)
+	require.Error(t, err)
+	_, _, err = selectVersion("foo/bar", versions, "3.0.0", StrategyMinor, nil)
+	var warning *versionWarning
+	require.ErrorAs(t, err, &warning)
+	require.Equal(t, "no stable version matches the minor strategy", warning.message)
+	_, _, err = selectVersion("foo/bar", versions, "1.0.0", "foo", nil)
+	require.Error(t, err)
+}
+
+func TestSelectVersionPlatforms(t *testing.T) {
+	versions := []*releaseVersion{
+		{version: "1.0.0", platforms: []string{"linux_amd64", "linux_arm64", "darwin_arm64"}},
+		{version: "1.1.0", platforms: []string{"linux_amd64", "linux_arm64", "darwin_arm64"}},
+		{version: "1.2.0", platforms: []string{"linux_amd64"}},
+		{version: "1.3.0-beta1", platforms: []string{"linux_amd64"}},
+	}
+	platforms := []string{"linux_amd64", "linux_arm64", "darwin_arm64"}
+
+	v, skipped, err := selectVersion("foo/bar", versions, "1.0.0", StrategyLatest, platforms)
+	require.NoError(t, err)
+	require.Equal(t, "1.1.0", v)
+	require.Len(t, skipped, 1)
+	require.Equal(t, "foo/bar", skipped[0].Name)
+	require.Equal(t, "1.2.0", skipped[0].Version)
+	require.Equal(t, "missing platforms linux_arm64, darwin_arm64", skipped[0].Reason)
+
+	// versions without known platforms are not skipped
+	v, skipped, err = selectVersion("foo/bar", newReleaseVersions("1.2.0"), "1.0.0", StrategyLatest, platforms)
+	require.NoError(t, err)
+	require.Equal(t, "1.2.0", v)
+	require.Empty(t, skipped)
+
+	// the current version is kept when every newer version is skipped
+	v, skipped, err = selectVersion("foo/bar", versions[2:], "1.0.0", StrategyLatest, platforms)
+	require.NoError(t, err)
+	require.Empty(t, v)
+	require.Len(t, skipped, 1)
+
+	// versions below the current constraint are never selected when newer versions are skipped
+	versions = []*releaseVersion{
+		{version: "3.5.2", platforms: []string{"linux_amd64", "darwin_arm64"}},
+		{version: "3.6.0", platforms: []string{"linux_amd64"}},
+	}
+	v, skipped, err = selectVersion("foo/bar", versions, "3.6.0", StrategyLatest, []string{"linux_amd64", "darwin_arm64"})
+	require.NoError(t, err)
+	require.Empty(t, v)
+	require.Len(t, skipped, 1)
+	require.Equal(t, "3.6.0", skipped[0].Version)
+}
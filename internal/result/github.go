@@ -0,0 +1,40 @@
+package result
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToGitHub renders every occurrence of an outdated version in the results as a GitHub Actions warning workflow command.
+func ToGitHub(rr []*Result) (string, error) {
+	lines := []string{}
+	for _, r := range rr {
+		for _, u := range r.Updated {
+			message := fmt.Sprintf("%s %s can be updated to %s", u.Name, u.OldVersion, u.NewVersion)
+			for _, o := range u.Occurrences {
+				properties := []string{
+					fmt.Sprintf("file=%s", escapeGitHubProperty(o.Path)),
+					fmt.Sprintf("line=%d", o.Range.Start.Line),
+					fmt.Sprintf("endLine=%d", o.Range.End.Line),
+					fmt.Sprintf("title=%s", escapeGitHubProperty(fmt.Sprintf("Outdated %s version", r.Title))),
+				}
+				lines = append(lines, fmt.Sprintf("::warning %s::%s", strings.Join(properties, ","), escapeGitHubData(message)))
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func escapeGitHubData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	s = strings.ReplaceAll(s, "\n", "%0A")
+	return s
+}
+
+func escapeGitHubProperty(s string) string {
+	s = escapeGitHubData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	s = strings.ReplaceAll(s, ",", "%2C")
+	return s
+}
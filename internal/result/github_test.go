@@ -0,0 +1,73 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHub(t *testing.T) {
+	rr := []*Result{
+		{
+			Title: "Provider",
+			Updated: []*Update{
+				{
+					Name:       "hashicorp/azurerm",
+					OldVersion: "2.35.0",
+					NewVersion: "2.53.0",
+					Occurrences: []*Occurrence{
+						{
+							Path:    "foo/main.tf",
+							Address: "required_providers.azurerm",
+							Range: hcl.Range{
+								Start: hcl.Pos{Line: 5, Column: 5},
+								End:   hcl.Pos{Line: 8, Column: 6},
+							},
+						},
+						{
+							Path:    "bar/main.tf",
+							Address: "required_providers.azurerm",
+							Range: hcl.Range{
+								Start: hcl.Pos{Line: 3, Column: 5},
+								End:   hcl.Pos{Line: 6, Column: 6},
+							},
+						},
+					},
+				},
+			},
+			Current: []*Current{},
+			Ignored: []*Ignore{},
+		},
+		{
+			Title: "Helm",
+			Updated: []*Update{
+				{
+					Name:       "ingress-nginx",
+					OldVersion: "3.35.0",
+					NewVersion: "4.0.1",
+					Occurrences: []*Occurrence{
+						{
+							Path:    "foo,bar/main.tf",
+							Address: "helm_release.ingress_nginx",
+							Range: hcl.Range{
+								Start: hcl.Pos{Line: 1, Column: 1},
+								End:   hcl.Pos{Line: 6, Column: 2},
+							},
+						},
+					},
+				},
+			},
+			Current: []*Current{},
+			Ignored: []*Ignore{},
+		},
+	}
+
+	out, err := ToGitHub(rr)
+	assert.NoError(t, err)
+	assert.Equal(t, githubExpected, out)
+}
+
+const githubExpected = `::warning file=foo/main.tf,line=5,endLine=8,title=Outdated Provider version::hashicorp/azurerm 2.35.0 can be updated to 2.53.0
+::warning file=bar/main.tf,line=3,endLine=6,title=Outdated Provider version::hashicorp/azurerm 2.35.0 can be updated to 2.53.0
+::warning file=foo%2Cbar/main.tf,line=1,endLine=6,title=Outdated Helm version::ingress-nginx 3.35.0 can be updated to 4.0.1`
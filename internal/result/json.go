@@ -0,0 +1,104 @@
+package result
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type jsonReport struct {
+	Updates  []*jsonUpdate  `json:"updates"`
+	Ignores  []*jsonIgnore  `json:"ignores"`
+	Skips    []*jsonSkip    `json:"skips"`
+	Warnings []*jsonWarning `json:"warnings"`
+}
+
+type jsonUpdate struct {
+	Ecosystem  string `json:"ecosystem"`
+	Path       string `json:"path"`
+	Address    string `json:"address"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	Name       string `json:"name"`
+	OldVersion string `json:"old_version"`
+	NewVersion string `json:"new_version"`
+	ReleaseURL string `json:"release_url"`
+}
+
+type jsonIgnore struct {
+	Ecosystem string `json:"ecosystem"`
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+type jsonSkip struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Reason    string `json:"reason"`
+}
+
+type jsonWarning struct {
+	Ecosystem string `json:"ecosystem"`
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+}
+
+// ToJSON renders every update occurrence and ignore in the results, unlike ToMarkdown duplicates across files are kept.
+func ToJSON(rr []*Result) (string, error) {
+	report := jsonReport{
+		Updates:  []*jsonUpdate{},
+		Ignores:  []*jsonIgnore{},
+		Skips:    []*jsonSkip{},
+		Warnings: []*jsonWarning{},
+	}
+	for _, r := range rr {
+		ecosystem := strings.ToLower(r.Title)
+		for _, u := range r.Updated {
+			for _, o := range u.Occurrences {
+				report.Updates = append(report.Updates, &jsonUpdate{
+					Ecosystem:  ecosystem,
+					Path:       o.Path,
+					Address:    o.Address,
+					StartLine:  o.Range.Start.Line,
+					EndLine:    o.Range.End.Line,
+					Name:       u.Name,
+					OldVersion: u.OldVersion,
+					NewVersion: u.NewVersion,
+					ReleaseURL: u.ReleaseURL,
+				})
+			}
+		}
+		for _, i := range r.Ignored {
+			report.Ignores = append(report.Ignores, &jsonIgnore{
+				Ecosystem: ecosystem,
+				Path:      i.Path,
+				Name:      i.Name,
+				Reason:    i.Reason,
+			})
+		}
+		for _, s := range filterUnique(r).Skipped {
+			report.Skips = append(report.Skips, &jsonSkip{
+				Ecosystem: ecosystem,
+				Name:      s.Name,
+				Version:   s.Version,
+				Reason:    s.Reason,
+			})
+		}
+		for _, w := range r.Warnings {
+			report.Warnings = append(report.Warnings, &jsonWarning{
+				Ecosystem: ecosystem,
+				Path:      w.Path,
+				Name:      w.Name,
+				Message:   w.Message,
+			})
+		}
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
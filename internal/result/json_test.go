@@ -0,0 +1,142 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	rr := []*Result{
+		{
+			Title: "Provider",
+			Updated: []*Update{
+				{
+					Name:       "hashicorp/azurerm",
+					OldVersion: "2.35.0",
+					NewVersion: "2.53.0",
+					ReleaseURL: "https://github.com/hashicorp/terraform-provider-azurerm/releases/tag/v2.53.0",
+					Occurrences: []*Occurrence{
+						{
+							Path:    "foo/main.tf",
+							Address: "required_providers.azurerm",
+							Range:   hcl.Range{Start: hcl.Pos{Line: 5}, End: hcl.Pos{Line: 8}},
+						},
+					},
+				},
+				{
+					Name:       "hashicorp/azurerm",
+					OldVersion: "2.35.0",
+					NewVersion: "2.53.0",
+					Occurrences: []*Occurrence{
+						{
+							Path:    "bar/main.tf",
+							Address: "required_providers.azurerm",
+							Range:   hcl.Range{Start: hcl.Pos{Line: 3}, End: hcl.Pos{Line: 6}},
+						},
+					},
+				},
+			},
+			Ignored: []*Ignore{},
+			Skipped: []*Skip{
+				{
+					Name:    "hashicorp/azurerm",
+					Version: "3.0.0",
+					Reason:  "missing platforms darwin_arm64",
+				},
+				{
+					Name:    "hashicorp/azurerm",
+					Version: "3.0.0",
+					Reason:  "missing platforms darwin_arm64",
+				},
+			},
+			Warnings: []*Warning{
+				{
+					Name:    "aws",
+					Path:    "foo/main.tf",
+					Message: "unsupported required_providers entry",
+				},
+			},
+		},
+		{
+			Title:   "Helm",
+			Updated: []*Update{},
+			Ignored: []*Ignore{
+				{
+					Name:   "aad-pod-identity",
+					Path:   "foo/main.tf",
+					Reason: IgnoreReasonAnnotation,
+				},
+			},
+		},
+	}
+
+	out, err := ToJSON(rr)
+	assert.NoError(t, err)
+	assert.Equal(t, jsonResult, out)
+}
+
+func TestJSONEmpty(t *testing.T) {
+	out, err := ToJSON([]*Result{})
+	assert.NoError(t, err)
+	assert.Equal(t, jsonEmptyResult, out)
+}
+
+const jsonResult = `{
+  "updates": [
+    {
+      "ecosystem": "provider",
+      "path": "foo/main.tf",
+      "address": "required_providers.azurerm",
+      "start_line": 5,
+      "end_line": 8,
+      "name": "hashicorp/azurerm",
+      "old_version": "2.35.0",
+      "new_version": "2.53.0",
+      "release_url": "https://github.com/hashicorp/terraform-provider-azurerm/releases/tag/v2.53.0"
+    },
+    {
+      "ecosystem": "provider",
+      "path": "bar/main.tf",
+      "address": "required_providers.azurerm",
+      "start_line": 3,
+      "end_line": 6,
+      "name": "hashicorp/azurerm",
+      "old_version": "2.35.0",
+      "new_version": "2.53.0",
+      "release_url": ""
+    }
+  ],
+  "ignores": [
+    {
+      "ecosystem": "helm",
+      "path": "foo/main.tf",
+      "name": "aad-pod-identity",
+      "reason": "annotation"
+    }
+  ],
+  "skips": [
+    {
+      "ecosystem": "provider",
+      "name": "hashicorp/azurerm",
+      "version": "3.0.0",
+      "reason": "missing platforms darwin_arm64"
+    }
+  ],
+  "warnings": [
+    {
+      "ecosystem": "provider",
+      "path": "foo/main.tf",
+      "name": "aws",
+      "message": "unsupported required_providers entry"
+    }
+  ]
+}`
+
+const jsonEmptyResult = `{
+  "updates": [],
+  "ignores": [],
+  "skips": [],
+  "warnings": []
+}`
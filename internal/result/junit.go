@@ -0,0 +1,85 @@
+package result
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// ToJUnit renders the results as a JUnit XML report with a test suite per result title and a test case per dependency.
+// Every occurrence of an outdated dependency is a failure and ignored dependencies are skipped.
+func ToJUnit(rr []*Result) (string, error) {
+	report := junitTestSuites{
+		Name:   toolName,
+		Suites: []*junitTestSuite{},
+	}
+	for _, r := range rr {
+		suite := &junitTestSuite{
+			Name:      r.Title,
+			TestCases: []*junitTestCase{},
+		}
+		for _, u := range r.Updated {
+			for _, o := range u.Occurrences {
+				suite.TestCases = append(suite.TestCases, &junitTestCase{
+					Name:      u.Name,
+					ClassName: o.Path,
+					Failure:   &junitMessage{Message: fmt.Sprintf("%s %s can be updated to %s", u.Name, u.OldVersion, u.NewVersion)},
+				})
+				suite.Failures++
+			}
+		}
+		for _, c := range r.Current {
+			suite.TestCases = append(suite.TestCases, &junitTestCase{
+				Name:      c.Name,
+				ClassName: c.Path,
+			})
+		}
+		for _, i := range r.Ignored {
+			suite.TestCases = append(suite.TestCases, &junitTestCase{
+				Name:      i.Name,
+				ClassName: i.Path,
+				Skipped:   &junitMessage{Message: fmt.Sprintf("ignored by %s", i.Reason)},
+			})
+		}
+		suite.Tests = len(suite.TestCases)
+		suite.Skipped = len(r.Ignored)
+
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+	}
+
+	b, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(b), nil
+}
@@ -0,0 +1,68 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJUnit(t *testing.T) {
+	rr := []*Result{
+		{
+			Title: "Provider",
+			Updated: []*Update{
+				{
+					Name:       "hashicorp/azurerm",
+					OldVersion: "2.35.0",
+					NewVersion: "2.53.0",
+					Occurrences: []*Occurrence{
+						{
+							Path:    "foo/main.tf",
+							Address: "required_providers.azurerm",
+							Range:   hcl.Range{Start: hcl.Pos{Line: 5}, End: hcl.Pos{Line: 8}},
+						},
+					},
+				},
+			},
+			Current: []*Current{
+				{
+					Name:    "hashicorp/aws",
+					Path:    "foo/main.tf",
+					Version: "3.59.0",
+				},
+			},
+			Ignored: []*Ignore{
+				{
+					Name:   "hashicorp/helm",
+					Path:   "bar/main.tf",
+					Reason: IgnoreReasonSelector,
+				},
+			},
+		},
+		{
+			Title:   "Helm",
+			Updated: []*Update{},
+			Current: []*Current{},
+			Ignored: []*Ignore{},
+		},
+	}
+
+	out, err := ToJUnit(rr)
+	assert.NoError(t, err)
+	assert.Equal(t, junitExpected, out)
+}
+
+const junitExpected = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="tf-latest-version" tests="3" failures="1" skipped="1">
+  <testsuite name="Provider" tests="3" failures="1" skipped="1">
+    <testcase name="hashicorp/azurerm" classname="foo/main.tf">
+      <failure message="hashicorp/azurerm 2.35.0 can be updated to 2.53.0"></failure>
+    </testcase>
+    <testcase name="hashicorp/aws" classname="foo/main.tf"></testcase>
+    <testcase name="hashicorp/helm" classname="bar/main.tf">
+      <skipped message="ignored by selector"></skipped>
+    </testcase>
+  </testsuite>
+  <testsuite name="Helm" tests="0" failures="0" skipped="0"></testsuite>
+</testsuites>`
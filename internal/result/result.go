@@ -3,48 +3,222 @@ package result
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strings"
 	"text/template"
+
+	"github.com/hashicorp/hcl/v2"
 )
 
-type Update struct {
-	Name       string
+const (
+	TitleProvider = "Provider"
+	TitleHelm     = "Helm"
+)
+
+const (
+	IgnoreReasonSelector   = "selector"
+	IgnoreReasonAnnotation = "annotation"
+)
+
+// titleOrder is the order in which results are sorted, unknown titles are sorted last by name.
+var titleOrder = map[string]int{
+	TitleProvider: 0,
+	TitleHelm:     1,
+}
+
+type Occurrence struct {
+	Path    string
+	Address string
+	Range   hcl.Range
+	// OldVersion is the version at the occurrence, it is set when updates are grouped.
 	OldVersion string
-	NewVersion string
+}
+
+func (o *Occurrence) String() string {
+	return fmt.Sprintf("%s:%d-%d (%s)", o.Path, o.Range.Start.Line, o.Range.End.Line, o.Address)
+}
+
+type Update struct {
+	Name        string
+	OldVersion  string
+	NewVersion  string
+	ReleaseURL  string
+	Occurrences []*Occurrence
+}
+
+// OldVersions returns the unique old versions of the occurrences, the old version of the update is used when they are not set.
+func (u *Update) OldVersions() []string {
+	versions := []string{}
+	seen := map[string]bool{}
+	for _, o := range u.Occurrences {
+		if o.OldVersion == "" || seen[o.OldVersion] {
+			continue
+		}
+		seen[o.OldVersion] = true
+		versions = append(versions, o.OldVersion)
+	}
+	if len(versions) == 0 {
+		return []string{u.OldVersion}
+	}
+	return versions
+}
+
+type Current struct {
+	Name    string
+	Path    string
+	Version string
 }
 
 type Ignore struct {
-	Name string
-	Path string
+	Name   string
+	Path   string
+	Reason string
+}
+
+// Skip is a version newer than the selected version which was not used.
+type Skip struct {
+	Name    string
+	Version string
+	Reason  string
+}
+
+// Warning is an entry which could not be checked, the rest of the file is still updated.
+type Warning struct {
+	Name    string
+	Path    string
+	Message string
 }
 
 type Result struct {
-	Title   string
-	Ignored []*Ignore
-	Updated []*Update
+	Title    string
+	Ignored  []*Ignore
+	Updated  []*Update
+	Current  []*Current
+	Skipped  []*Skip
+	Warnings []*Warning
 }
 
 func NewResult(title string) *Result {
 	return &Result{
-		Title:   title,
-		Ignored: []*Ignore{},
-		Updated: []*Update{},
+		Title:    title,
+		Ignored:  []*Ignore{},
+		Updated:  []*Update{},
+		Current:  []*Current{},
+		Skipped:  []*Skip{},
+		Warnings: []*Warning{},
+	}
+}
+
+func (r *Result) HasUpdates() bool {
+	return len(r.Updated) > 0
+}
+
+func HasUpdates(rr []*Result) bool {
+	for _, r := range rr {
+		if r.HasUpdates() {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort orders the results by title and the entries in each result by name, path and line.
+func Sort(rr []*Result) []*Result {
+	sorted := append([]*Result{}, rr...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		oi, iok := titleOrder[sorted[i].Title]
+		oj, jok := titleOrder[sorted[j].Title]
+		if iok != jok {
+			return iok
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return sorted[i].Title < sorted[j].Title
+	})
+	for _, r := range sorted {
+		r.sortEntries()
 	}
+	return sorted
+}
+
+func (r *Result) sortEntries() {
+	for _, u := range r.Updated {
+		sort.SliceStable(u.Occurrences, func(i, j int) bool {
+			return lessPathLine(u.Occurrences[i], u.Occurrences[j])
+		})
+	}
+	sort.SliceStable(r.Updated, func(i, j int) bool {
+		ui, uj := r.Updated[i], r.Updated[j]
+		if ui.Name != uj.Name {
+			return ui.Name < uj.Name
+		}
+		if len(ui.Occurrences) == 0 || len(uj.Occurrences) == 0 {
+			return len(ui.Occurrences) < len(uj.Occurrences)
+		}
+		return lessPathLine(ui.Occurrences[0], uj.Occurrences[0])
+	})
+	sort.SliceStable(r.Ignored, func(i, j int) bool {
+		if r.Ignored[i].Name != r.Ignored[j].Name {
+			return r.Ignored[i].Name < r.Ignored[j].Name
+		}
+		return r.Ignored[i].Path < r.Ignored[j].Path
+	})
+	sort.SliceStable(r.Current, func(i, j int) bool {
+		if r.Current[i].Name != r.Current[j].Name {
+			return r.Current[i].Name < r.Current[j].Name
+		}
+		return r.Current[i].Path < r.Current[j].Path
+	})
+	sort.SliceStable(r.Skipped, func(i, j int) bool {
+		if r.Skipped[i].Name != r.Skipped[j].Name {
+			return r.Skipped[i].Name < r.Skipped[j].Name
+		}
+		return r.Skipped[i].Version < r.Skipped[j].Version
+	})
+	sort.SliceStable(r.Warnings, func(i, j int) bool {
+		if r.Warnings[i].Path != r.Warnings[j].Path {
+			return r.Warnings[i].Path < r.Warnings[j].Path
+		}
+		return r.Warnings[i].Name < r.Warnings[j].Name
+	})
+}
+
+func lessPathLine(a, b *Occurrence) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.Range.Start.Line < b.Range.Start.Line
 }
 
 func filterUnique(res *Result) *Result {
-	existingUpdated := map[string]string{}
+	existingUpdated := map[string]*Update{}
 	updated := []*Update{}
 	for _, u := range res.Updated {
-		v, ok := existingUpdated[u.Name]
-		// result already in list
-		if ok && v == u.NewVersion {
+		// the old version is kept on each occurrence as it can differ between files
+		occurrences := []*Occurrence{}
+		for _, o := range u.Occurrences {
+			occurrence := *o
+			occurrence.OldVersion = u.OldVersion
+			occurrences = append(occurrences, &occurrence)
+		}
+		key := fmt.Sprintf("%s:%s", u.Name, u.NewVersion)
+		// group occurrences if result already in list
+		if exist, ok := existingUpdated[key]; ok {
+			exist.Occurrences = append(exist.Occurrences, occurrences...)
 			continue
 		}
 
-		existingUpdated[u.Name] = u.NewVersion
-		updated = append(updated, u)
+		unique := &Update{
+			Name:        u.Name,
+			OldVersion:  u.OldVersion,
+			NewVersion:  u.NewVersion,
+			ReleaseURL:  u.ReleaseURL,
+			Occurrences: occurrences,
+		}
+		existingUpdated[key] = unique
+		updated = append(updated, unique)
 	}
-	res.Updated = updated
 
 	existingIgnored := map[string]string{}
 	ignored := []*Ignore{}
@@ -58,14 +232,33 @@ func filterUnique(res *Result) *Result {
 		existingIgnored[u.Name] = u.Path
 		ignored = append(ignored, u)
 	}
-	res.Ignored = ignored
 
-	return res
+	existingSkipped := map[string]bool{}
+	skipped := []*Skip{}
+	for _, s := range res.Skipped {
+		key := fmt.Sprintf("%s:%s", s.Name, s.Version)
+		// the same version is skipped for every file using the provider
+		if existingSkipped[key] {
+			continue
+		}
+
+		existingSkipped[key] = true
+		skipped = append(skipped, s)
+	}
+
+	return &Result{
+		Title:    res.Title,
+		Ignored:  ignored,
+		Updated:  updated,
+		Current:  res.Current,
+		Skipped:  skipped,
+		Warnings: res.Warnings,
+	}
 }
 
 func (r *Result) ToMarkdown() (string, error) {
 	res := filterUnique(r)
-	if len(res.Updated) == 0 && len(res.Ignored) == 0 {
+	if len(res.Updated) == 0 && len(res.Ignored) == 0 && len(res.Skipped) == 0 && len(res.Warnings) == 0 {
 		return fmt.Sprintf("# %s\nNo Changes.", r.Title), nil
 	}
 
@@ -83,13 +276,25 @@ func (r *Result) ToMarkdown() (string, error) {
 	return out.String(), nil
 }
 
+func ToMarkdown(rr []*Result) (string, error) {
+	outputs := []string{}
+	for _, r := range rr {
+		output, err := r.ToMarkdown()
+		if err != nil {
+			return "", err
+		}
+		outputs = append(outputs, output)
+	}
+	return strings.Join(outputs, "\n\n"), nil
+}
+
 const mdTemplate = `# {{ .Title }}
 {{- if .Updated }}
 ## Updated
-| Name | Old Version | New Version |
-| --- | --- | --- |
+| Name | Old Version | New Version | Locations |
+| --- | --- | --- | --- |
 {{- range .Updated }}
-| {{ .Name }} | {{ .OldVersion }} | {{ .NewVersion }} |
+| {{ .Name }} | {{ template "oldVersions" . }} | {{ template "newVersion" . }} | {{ template "locations" . }} |
 {{- end }}
 {{- end }}
 
@@ -101,4 +306,27 @@ const mdTemplate = `# {{ .Title }}
 | {{ .Name }} | {{ .Path }} |
 {{- end -}}
 {{- end -}}
+
+{{- if .Skipped }}
+## Skipped
+| Name | Version | Reason |
+| --- | --- | --- |
+{{- range .Skipped }}
+| {{ .Name }} | {{ .Version }} | {{ .Reason }} |
+{{- end -}}
+{{- end -}}
+
+{{- if .Warnings }}
+## Warnings
+| Name | Path | Message |
+| --- | --- | --- |
+{{- range .Warnings }}
+| {{ .Name }} | {{ .Path }} | {{ .Message }} |
+{{- end -}}
+{{- end -}}
+
+{{- define "oldVersions" }}{{ range $i, $v := .OldVersions }}{{ if $i }}<br>{{ end }}{{ $v }}{{ end }}{{ end -}}
+{{- define "newVersion" }}{{ if .ReleaseURL }}[{{ .NewVersion }}]({{ .ReleaseURL }}){{ else }}{{ .NewVersion }}{{ end }}{{ end -}}
+{{- define "locations" }}{{ $mixed := gt (len .OldVersions) 1 }}
+{{- range $i, $o := .Occurrences }}{{ if $i }}<br>{{ end }}{{ $o }}{{ if $mixed }} from {{ $o.OldVersion }}{{ end }}{{ end }}{{ end -}}
 `
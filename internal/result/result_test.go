@@ -3,6 +3,7 @@ package result
 import (
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,16 +38,31 @@ func TestUpdated(t *testing.T) {
 				Name:       "foo",
 				OldVersion: "0",
 				NewVersion: "1",
+				ReleaseURL: "https://example.com/foo",
 			},
 			{
 				Name:       "bar",
 				OldVersion: "1",
 				NewVersion: "2",
+				Occurrences: []*Occurrence{
+					{
+						Path:    "foo/main.tf",
+						Address: "helm_release.bar",
+						Range:   hcl.Range{Start: hcl.Pos{Line: 1}, End: hcl.Pos{Line: 6}},
+					},
+				},
 			},
 			{
 				Name:       "bar",
 				OldVersion: "1",
 				NewVersion: "2",
+				Occurrences: []*Occurrence{
+					{
+						Path:    "bar/main.tf",
+						Address: "helm_release.bar",
+						Range:   hcl.Range{Start: hcl.Pos{Line: 8}, End: hcl.Pos{Line: 13}},
+					},
+				},
 			},
 		},
 		Ignored: []*Ignore{},
@@ -57,6 +73,43 @@ func TestUpdated(t *testing.T) {
 	assert.Equal(t, updatedResult, md)
 }
 
+func TestUpdatedOldVersions(t *testing.T) {
+	res := Result{
+		Title: "test",
+		Updated: []*Update{
+			{
+				Name:       "bar",
+				OldVersion: "1",
+				NewVersion: "2",
+				Occurrences: []*Occurrence{
+					{
+						Path:    "foo/main.tf",
+						Address: "helm_release.bar",
+						Range:   hcl.Range{Start: hcl.Pos{Line: 1}, End: hcl.Pos{Line: 6}},
+					},
+				},
+			},
+			{
+				Name:       "bar",
+				OldVersion: "0",
+				NewVersion: "2",
+				Occurrences: []*Occurrence{
+					{
+						Path:    "bar/main.tf",
+						Address: "helm_release.bar",
+						Range:   hcl.Range{Start: hcl.Pos{Line: 8}, End: hcl.Pos{Line: 13}},
+					},
+				},
+			},
+		},
+		Ignored: []*Ignore{},
+	}
+
+	md, err := res.ToMarkdown()
+	assert.NoError(t, err)
+	assert.Equal(t, updatedOldVersionsResult, md)
+}
+
 func TestIgnored(t *testing.T) {
 	res := Result{
 		Title:   "test",
@@ -78,6 +131,49 @@ func TestIgnored(t *testing.T) {
 	assert.Equal(t, ignoredResult, md)
 }
 
+func TestSkipped(t *testing.T) {
+	res := Result{
+		Title:   "test",
+		Updated: []*Update{},
+		Ignored: []*Ignore{},
+		Skipped: []*Skip{
+			{
+				Name:    "bar",
+				Version: "2.0.0",
+				Reason:  "missing platforms linux_arm64",
+			},
+			{
+				Name:    "bar",
+				Version: "2.0.0",
+				Reason:  "missing platforms linux_arm64",
+			},
+		},
+	}
+
+	md, err := res.ToMarkdown()
+	assert.NoError(t, err)
+	assert.Equal(t, skippedResult, md)
+}
+
+func TestWarnings(t *testing.T) {
+	res := Result{
+		Title:   "test",
+		Updated: []*Update{},
+		Ignored: []*Ignore{},
+		Warnings: []*Warning{
+			{
+				Name:    "bar",
+				Path:    "baz",
+				Message: "missing version constraint",
+			},
+		},
+	}
+
+	md, err := res.ToMarkdown()
+	assert.NoError(t, err)
+	assert.Equal(t, warningsResult, md)
+}
+
 func TestNone(t *testing.T) {
 	res := Result{
 		Title:   "test",
@@ -90,11 +186,29 @@ func TestNone(t *testing.T) {
 	assert.Equal(t, noneResult, md)
 }
 
+func TestHasUpdates(t *testing.T) {
+	rr := []*Result{
+		{
+			Title:   "foo",
+			Updated: []*Update{},
+			Ignored: []*Ignore{{Name: "bar", Path: "baz"}},
+		},
+	}
+	assert.False(t, HasUpdates(rr))
+
+	rr = append(rr, &Result{
+		Title:   "bar",
+		Updated: []*Update{{Name: "foo", OldVersion: "0", NewVersion: "1", Occurrences: []*Occurrence{}}},
+		Ignored: []*Ignore{},
+	})
+	assert.True(t, HasUpdates(rr))
+}
+
 const bothResult = `# test
 ## Updated
-| Name | Old Version | New Version |
-| --- | --- | --- |
-| foo | 0 | 1 |
+| Name | Old Version | New Version | Locations |
+| --- | --- | --- | --- |
+| foo | 0 | 1 |  |
 ## Ignored
 | Name | Path |
 | --- | --- |
@@ -102,10 +216,16 @@ const bothResult = `# test
 
 const updatedResult = `# test
 ## Updated
-| Name | Old Version | New Version |
-| --- | --- | --- |
-| foo | 0 | 1 |
-| bar | 1 | 2 |`
+| Name | Old Version | New Version | Locations |
+| --- | --- | --- | --- |
+| foo | 0 | [1](https://example.com/foo) |  |
+| bar | 1 | 2 | foo/main.tf:1-6 (helm_release.bar)<br>bar/main.tf:8-13 (helm_release.bar) |`
+
+const updatedOldVersionsResult = `# test
+## Updated
+| Name | Old Version | New Version | Locations |
+| --- | --- | --- | --- |
+| bar | 1<br>0 | 2 | foo/main.tf:1-6 (helm_release.bar) from 1<br>bar/main.tf:8-13 (helm_release.bar) from 0 |`
 
 const ignoredResult = `# test
 ## Ignored
@@ -115,3 +235,15 @@ const ignoredResult = `# test
 
 const noneResult = `# test
 No Changes.`
+
+const skippedResult = `# test
+## Skipped
+| Name | Version | Reason |
+| --- | --- | --- |
+| bar | 2.0.0 | missing platforms linux_arm64 |`
+
+const warningsResult = `# test
+## Warnings
+| Name | Path | Message |
+| --- | --- | --- |
+| bar | baz | missing version constraint |`
@@ -0,0 +1,128 @@
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "tf-latest-version"
+	toolURI      = "https://github.com/XenitAB/tf-latest-version"
+)
+
+type sarifReport struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId"`
+	Level     string           `json:"level"`
+	Message   sarifMessage     `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// ToSARIF renders every occurrence of an outdated version in the results as a SARIF 2.1.0 log with one rule per result title.
+func ToSARIF(rr []*Result) (string, error) {
+	run := &sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          []*sarifRule{},
+			},
+		},
+		Results: []*sarifResult{},
+	}
+	for _, r := range rr {
+		ruleID := fmt.Sprintf("outdated-%s", strings.ToLower(r.Title))
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &sarifRule{
+			ID:               ruleID,
+			ShortDescription: sarifMessage{Text: fmt.Sprintf("Outdated %s version", r.Title)},
+		})
+		for _, u := range r.Updated {
+			for _, o := range u.Occurrences {
+				run.Results = append(run.Results, &sarifResult{
+					RuleID:    ruleID,
+					Level:     "warning",
+					Message:   sarifMessage{Text: fmt.Sprintf("%s %s can be updated to %s", u.Name, u.OldVersion, u.NewVersion)},
+					Locations: []*sarifLocation{newSARIFLocation(o)},
+				})
+			}
+		}
+	}
+
+	report := sarifReport{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{run},
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func newSARIFLocation(o *Occurrence) *sarifLocation {
+	return &sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(o.Path)},
+			Region: sarifRegion{
+				StartLine:   o.Range.Start.Line,
+				StartColumn: o.Range.Start.Column,
+				EndLine:     o.Range.End.Line,
+				EndColumn:   o.Range.End.Column,
+			},
+		},
+	}
+}
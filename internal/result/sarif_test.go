@@ -0,0 +1,86 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSARIF(t *testing.T) {
+	rr := []*Result{
+		{
+			Title: "Provider",
+			Updated: []*Update{
+				{
+					Name:       "hashicorp/azurerm",
+					OldVersion: "2.35.0",
+					NewVersion: "2.53.0",
+					Occurrences: []*Occurrence{
+						{
+							Path:    "foo/main.tf",
+							Address: "required_providers.azurerm",
+							Range: hcl.Range{
+								Filename: "foo/main.tf",
+								Start:    hcl.Pos{Line: 5, Column: 5},
+								End:      hcl.Pos{Line: 8, Column: 6},
+							},
+						},
+					},
+				},
+			},
+			Ignored: []*Ignore{},
+		},
+	}
+
+	out, err := ToSARIF(rr)
+	assert.NoError(t, err)
+	assert.Equal(t, sarifExpected, out)
+}
+
+const sarifExpected = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "tf-latest-version",
+          "informationUri": "https://github.com/XenitAB/tf-latest-version",
+          "rules": [
+            {
+              "id": "outdated-provider",
+              "shortDescription": {
+                "text": "Outdated Provider version"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "outdated-provider",
+          "level": "warning",
+          "message": {
+            "text": "hashicorp/azurerm 2.35.0 can be updated to 2.53.0"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "foo/main.tf"
+                },
+                "region": {
+                  "startLine": 5,
+                  "startColumn": 5,
+                  "endLine": 8,
+                  "endColumn": 6
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
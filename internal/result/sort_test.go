@@ -0,0 +1,76 @@
+package result
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func newOccurrence(path string, line int) *Occurrence {
+	return &Occurrence{
+		Path:    path,
+		Address: "required_providers.foo",
+		Range:   hcl.Range{Start: hcl.Pos{Line: line}, End: hcl.Pos{Line: line + 3}},
+	}
+}
+
+func unsortedResults() []*Result {
+	return []*Result{
+		{
+			Title: TitleHelm,
+			Updated: []*Update{
+				{Name: "ingress-nginx", OldVersion: "3.35.0", NewVersion: "4.0.1", Occurrences: []*Occurrence{newOccurrence("b/main.tf", 1)}},
+				{Name: "cert-manager", OldVersion: "v1.3.1", NewVersion: "v1.5.3", Occurrences: []*Occurrence{newOccurrence("b/main.tf", 9)}},
+				{Name: "ingress-nginx", OldVersion: "3.35.0", NewVersion: "4.0.1", Occurrences: []*Occurrence{newOccurrence("a/main.tf", 1)}},
+			},
+			Ignored: []*Ignore{
+				{Name: "podinfo", Path: "b/main.tf", Reason: IgnoreReasonAnnotation},
+				{Name: "podinfo", Path: "a/main.tf", Reason: IgnoreReasonAnnotation},
+				{Name: "aad-pod-identity", Path: "c/main.tf", Reason: IgnoreReasonSelector},
+			},
+			Current: []*Current{},
+		},
+		{
+			Title: TitleProvider,
+			Updated: []*Update{
+				{Name: "hashicorp/azurerm", OldVersion: "2.35.0", NewVersion: "2.53.0", Occurrences: []*Occurrence{newOccurrence("b/main.tf", 20)}},
+				{Name: "hashicorp/azurerm", OldVersion: "2.35.0", NewVersion: "2.53.0", Occurrences: []*Occurrence{newOccurrence("b/main.tf", 4)}},
+				{Name: "hashicorp/aws", OldVersion: "3.58.0", NewVersion: "3.59.0", Occurrences: []*Occurrence{newOccurrence("a/main.tf", 4)}},
+			},
+			Ignored: []*Ignore{},
+			Current: []*Current{},
+		},
+	}
+}
+
+func TestSortGolden(t *testing.T) {
+	out, err := ToMarkdown(Sort(unsortedResults()))
+	require.NoError(t, err)
+
+	golden := filepath.Join("testdata", "sorted.golden")
+	if *updateGolden {
+		err := os.WriteFile(golden, []byte(out), 0o600)
+		require.NoError(t, err)
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), out)
+}
+
+func TestSortStable(t *testing.T) {
+	first, err := ToJSON(Sort(unsortedResults()))
+	require.NoError(t, err)
+
+	rr := unsortedResults()
+	rr[0], rr[1] = rr[1], rr[0]
+	rr[0].Updated[0], rr[0].Updated[2] = rr[0].Updated[2], rr[0].Updated[0]
+	second, err := ToJSON(Sort(rr))
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+}
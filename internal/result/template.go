@@ -0,0 +1,100 @@
+package result
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/xenitab/tf-provider-latest/internal/constraint"
+)
+
+const (
+	DiffTypeMajor   = "major"
+	DiffTypeMinor   = "minor"
+	DiffTypePatch   = "patch"
+	DiffTypeNone    = "none"
+	DiffTypeUnknown = "unknown"
+)
+
+// TemplateData is the data passed to user supplied templates.
+type TemplateData struct {
+	// Results contains one result per ecosystem with every update, ignore and up to date version.
+	Results []*Result
+	// Errors contains the errors which stopped the run.
+	Errors []string
+}
+
+var templateFuncs = template.FuncMap{
+	"diffType": DiffType,
+	"unique":   filterUnique,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"join":     strings.Join,
+}
+
+// ToTemplate renders the results and errors of a run with the given text/template.
+func ToTemplate(text string, rr []*Result, errs []error) (string, error) {
+	tmpl, err := template.New("user").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	data := TemplateData{
+		Results: rr,
+		Errors:  []string{},
+	}
+	for _, e := range errs {
+		data.Errors = append(data.Errors, e.Error())
+	}
+
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, data)
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// DiffType returns the most significant semver segment which differs between the versions. Provider version
+// constraints such as "~> 2.53" are compared using the version they are anchored to.
+func DiffType(oldVersion, newVersion string) string {
+	o, err := baseVersion(oldVersion)
+	if err != nil {
+		return DiffTypeUnknown
+	}
+	n, err := baseVersion(newVersion)
+	if err != nil {
+		return DiffTypeUnknown
+	}
+
+	switch {
+	case o.Major() != n.Major():
+		return DiffTypeMajor
+	case o.Minor() != n.Minor():
+		return DiffTypeMinor
+	case o.Patch() != n.Patch():
+		return DiffTypePatch
+	default:
+		return DiffTypeNone
+	}
+}
+
+// baseVersion returns the version, or the version a provider version constraint is anchored to.
+func baseVersion(s string) (*semver.Version, error) {
+	// chart versions can contain build metadata which is not part of a constraint
+	if v, err := semver.NewVersion(s); err == nil {
+		return v, nil
+	}
+	cc, err := constraint.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	v := cc.Base()
+	if v == nil {
+		return nil, fmt.Errorf("no version in constraint %q", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,91 @@
+package result
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+	rr := []*Result{
+		{
+			Title: "Provider",
+			Updated: []*Update{
+				{
+					Name:       "hashicorp/azurerm",
+					OldVersion: "2.35.0",
+					NewVersion: "2.53.0",
+				},
+				{
+					Name:       "hashicorp/azurerm",
+					OldVersion: "2.35.0",
+					NewVersion: "2.53.0",
+				},
+				{
+					Name:       "hashicorp/aws",
+					OldVersion: "3.59.0",
+					NewVersion: "4.0.0",
+				},
+			},
+			Ignored: []*Ignore{
+				{
+					Name:   "hashicorp/helm",
+					Path:   "foo/main.tf",
+					Reason: IgnoreReasonAnnotation,
+				},
+			},
+			Current: []*Current{},
+		},
+	}
+
+	out, err := ToTemplate(testTemplate, rr, []error{errors.New("foobar")})
+	assert.NoError(t, err)
+	assert.Equal(t, templateExpected, out)
+}
+
+func TestTemplateInvalid(t *testing.T) {
+	_, err := ToTemplate("{{ .Foo }", []*Result{}, nil)
+	assert.Error(t, err)
+}
+
+func TestDiffType(t *testing.T) {
+	tests := []struct {
+		oldVersion string
+		newVersion string
+		expected   string
+	}{
+		{oldVersion: "1.0.0", newVersion: "2.0.0", expected: DiffTypeMajor},
+		{oldVersion: "1.0.0", newVersion: "1.1.0", expected: DiffTypeMinor},
+		{oldVersion: "v1.0.0", newVersion: "v1.0.1", expected: DiffTypePatch},
+		{oldVersion: "1.0.0", newVersion: "1.0.0", expected: DiffTypeNone},
+		{oldVersion: "~> 1.0", newVersion: "2.0.0", expected: DiffTypeMajor},
+		{oldVersion: "~> 2.53", newVersion: "~> 2.54", expected: DiffTypeMinor},
+		{oldVersion: "~> 2.53.0", newVersion: "~> 2.53.1", expected: DiffTypePatch},
+		{oldVersion: ">= 2.0, < 3.0", newVersion: ">= 3.0, < 4.0", expected: DiffTypeMajor},
+		{oldVersion: "< 3.0", newVersion: "< 3.5", expected: DiffTypeUnknown},
+		{oldVersion: "latest", newVersion: "2.0.0", expected: DiffTypeUnknown},
+		{oldVersion: "1.0.0+build.1", newVersion: "1.0.1+build.2", expected: DiffTypePatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.oldVersion+"-"+tt.newVersion, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DiffType(tt.oldVersion, tt.newVersion))
+		})
+	}
+}
+
+const testTemplate = `{{- range .Results }}{{ lower .Title }}:
+{{- range (unique .).Updated }}
+- {{ .Name }} {{ .OldVersion }} -> {{ .NewVersion }} ({{ diffType .OldVersion .NewVersion }})
+{{- end }}
+{{- range .Ignored }}
+- {{ .Name }} ignored by {{ .Reason }}
+{{- end }}
+{{ end }}
+{{- range .Errors }}error: {{ . }}{{ end }}`
+
+const templateExpected = `provider:
+- hashicorp/azurerm 2.35.0 -> 2.53.0 (minor)
+- hashicorp/aws 3.59.0 -> 4.0.0 (major)
+- hashicorp/helm ignored by annotation
+error: foobar`
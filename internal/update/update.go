@@ -1,22 +1,96 @@
 package update
 
 import (
+	"fmt"
 	iofs "io/fs"
 	"path/filepath"
-	"strings"
+	"sync"
 
 	"github.com/spf13/afero"
 
+	"github.com/xenitab/tf-provider-latest/internal/cliconfig"
 	"github.com/xenitab/tf-provider-latest/internal/helm"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
 	"github.com/xenitab/tf-provider-latest/internal/provider"
 	"github.com/xenitab/tf-provider-latest/internal/result"
 )
 
-const TerraformExtension = ".tf"
+const (
+	DefaultConcurrency = 8
+
+	TerraformExtension = ".tf"
+	TofuExtension      = ".tofu"
+)
+
+type Options struct {
+	ProviderSelector *[]string
+	// ProviderStrategy limits which provider versions are selected, one of the provider strategies.
+	ProviderStrategy string
+	// ProviderPlatforms are the os_arch platforms every selected provider version has to be built for.
+	ProviderPlatforms []string
+	HelmSelector      *[]string
+	CLIConfig         *cliconfig.Config
+	// RegistryHost is used for provider sources without hostname, it is detected per directory when empty.
+	RegistryHost string
+	// HTTP configures the retries, timeouts and TLS of registry and chart repository requests.
+	HTTP httpclient.Options
+	// Concurrency is the number of providers and charts which are resolved at the same time.
+	Concurrency int
+}
+
+func Update(fs afero.Fs, path string, opts Options) ([]*result.Result, error) {
+	// lookups are shared by all files so that every dependency is resolved once per run
+	client, err := httpclient.NewTLSClient(fs, opts.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create http client: %w", err)
+	}
+	helmRepository := helm.NewHelmRepository(client)
+	registries := provider.NewRegistries(fs, client, opts.CLIConfig)
+
+	paths, err := findFiles(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// resolve the dependencies of all files concurrently before updating, the results are cached by the lookups
+	lookups, err := discover(fs, paths, opts, helmRepository, registries)
+	if err != nil {
+		return nil, err
+	}
+	resolve(lookups, opts.Concurrency)
 
-func Update(fs afero.Fs, path string, providerSelector *[]string, helmSelector *[]string) (string, error) {
 	resMap := map[string]*result.Result{}
+	for _, path := range paths {
+		helmResult, err := helm.Update(fs, path, helmRepository, opts.HelmSelector)
+		if err != nil {
+			return nil, err
+		}
+		resMap = merge(resMap, helmResult)
+		host, err := registryHost(fs, filepath.Dir(path), opts)
+		if err != nil {
+			return nil, err
+		}
+		reg, err := registries.Get(host)
+		if err != nil {
+			return nil, err
+		}
+		providerResult, err := provider.Update(fs, path, reg, opts.ProviderSelector, opts.ProviderStrategy, opts.ProviderPlatforms)
+		if err != nil {
+			return nil, err
+		}
+		resMap = merge(resMap, providerResult)
+	}
 
+	rr := []*result.Result{}
+	for _, r := range resMap {
+		rr = append(rr, r)
+	}
+	return result.Sort(rr), nil
+}
+
+// findFiles returns the Terraform and OpenTofu files in the directory and its sub directories.
+func findFiles(fs afero.Fs, path string) ([]string, error) {
+	paths := []string{}
 	err := afero.Walk(fs, path, func(path string, info iofs.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -24,36 +98,91 @@ func Update(fs afero.Fs, path string, providerSelector *[]string, helmSelector *
 		if info.IsDir() {
 			return nil
 		}
-		if filepath.Ext(info.Name()) != TerraformExtension {
+		ext := filepath.Ext(info.Name())
+		if ext != TerraformExtension && ext != TofuExtension {
 			return nil
 		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
 
-		helmResult, err := helm.Update(fs, path, helm.NewHelmRepository(), helmSelector)
+// discover returns the lookups of every dependency in the files, deduplicated by ecosystem and dependency.
+func discover(
+	fs afero.Fs, paths []string, opts Options, helmRepository helm.Repository, registries *provider.Registries,
+) (map[string]func(), error) {
+	lookups := map[string]func(){}
+	for _, path := range paths {
+		helmLookups, err := helm.Lookups(fs, path, helmRepository, opts.HelmSelector)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		resMap = merge(resMap, helmResult)
-		providerResult, err := provider.Update(fs, path, provider.NewHashicorpRegistry(), providerSelector)
+		for k, fn := range helmLookups {
+			lookups[fmt.Sprintf("helm/%s", k)] = fn
+		}
+		host, err := registryHost(fs, filepath.Dir(path), opts)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		resMap = merge(resMap, providerResult)
+		reg, err := registries.Get(host)
+		if err != nil {
+			return nil, err
+		}
+		providerLookups, err := provider.Lookups(fs, path, reg, opts.ProviderSelector)
+		if err != nil {
+			return nil, err
+		}
+		for k, fn := range providerLookups {
+			lookups[fmt.Sprintf("provider/%s/%s", host, k)] = fn
+		}
+	}
+	return lookups, nil
+}
 
-		return nil
-	})
+// resolve runs the lookups with at most concurrency lookups at the same time.
+func resolve(lookups map[string]func(), concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	jobs := make(chan func())
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for fn := range jobs {
+				fn()
+			}
+		}()
+	}
+	for _, fn := range lookups {
+		jobs <- fn
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// registryHost returns the OpenTofu registry as default for directories containing OpenTofu files unless the registry
+// host is set.
+func registryHost(fs afero.Fs, dir string, opts Options) (string, error) {
+	if opts.RegistryHost != "" {
+		return opts.RegistryHost, nil
+	}
+
+	infos, err := afero.ReadDir(fs, dir)
 	if err != nil {
 		return "", err
 	}
-
-	outputs := []string{}
-	for _, r := range resMap {
-		output, err := r.ToMarkdown()
-		if err != nil {
-			return "", err
+	for _, info := range infos {
+		if !info.IsDir() && filepath.Ext(info.Name()) == TofuExtension {
+			return provider.OpenTofuRegistryHost, nil
 		}
-		outputs = append(outputs, output)
 	}
-	return strings.Join(outputs, "\n\n"), nil
+	return provider.TerraformRegistryHost, nil
 }
 
 func merge(resMap map[string]*result.Result, res *result.Result) map[string]*result.Result {
@@ -65,6 +194,9 @@ func merge(resMap map[string]*result.Result, res *result.Result) map[string]*res
 
 	exist.Updated = append(exist.Updated, res.Updated...)
 	exist.Ignored = append(exist.Ignored, res.Ignored...)
+	exist.Current = append(exist.Current, res.Current...)
+	exist.Skipped = append(exist.Skipped, res.Skipped...)
+	exist.Warnings = append(exist.Warnings, res.Warnings...)
 	resMap[res.Title] = exist
 	return resMap
 }
@@ -0,0 +1,65 @@
+package update
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xenitab/tf-provider-latest/internal/provider"
+)
+
+func TestRegistryHost(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "/tmp/terraform/main.tf", []byte{}, os.FileMode(0644))
+	require.NoError(t, err)
+	err = afero.WriteFile(fs, "/tmp/tofu/main.tofu", []byte{}, os.FileMode(0644))
+	require.NoError(t, err)
+
+	host, err := registryHost(fs, "/tmp/terraform", Options{})
+	require.NoError(t, err)
+	require.Equal(t, provider.TerraformRegistryHost, host)
+
+	host, err = registryHost(fs, "/tmp/tofu", Options{})
+	require.NoError(t, err)
+	require.Equal(t, provider.OpenTofuRegistryHost, host)
+
+	host, err = registryHost(fs, "/tmp/tofu", Options{RegistryHost: provider.TerraformRegistryHost})
+	require.NoError(t, err)
+	require.Equal(t, provider.TerraformRegistryHost, host)
+}
+
+func TestResolve(t *testing.T) {
+	mu := sync.Mutex{}
+	running, maxRunning, calls := 0, 0, 0
+	lookups := map[string]func(){}
+	for i := 0; i < 20; i++ {
+		lookups[fmt.Sprintf("lookup-%d", i)] = func() {
+			mu.Lock()
+			running++
+			calls++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}
+	}
+
+	resolve(lookups, 4)
+	require.Equal(t, 20, calls)
+	require.LessOrEqual(t, maxRunning, 4)
+	require.Greater(t, maxRunning, 1)
+
+	calls, maxRunning = 0, 0
+	resolve(lookups, 0)
+	require.Equal(t, 20, calls)
+	require.Equal(t, 1, maxRunning)
+}
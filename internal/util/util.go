@@ -2,6 +2,7 @@ package util
 
 import (
 	"errors"
+	"os"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -33,11 +34,8 @@ func ReadHCLFile(fs afero.Fs, path string) (*hcl.File, *hclwrite.File, []*annota
 }
 
 func ReplaceHCLFile(fs afero.Fs, path string, hclFile *hclwrite.File) error {
-	err := fs.Remove(path)
-	if err != nil {
-		return err
-	}
-	file, err := fs.Create(path)
+	// Truncate instead of removing the file so that copy on write filesystems can be used
+	file, err := fs.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
 	if err != nil {
 		return err
 	}
@@ -5,12 +5,52 @@ import (
 	"io"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/afero"
 	flag "github.com/spf13/pflag"
+
+	"github.com/xenitab/tf-provider-latest/internal/cliconfig"
+	"github.com/xenitab/tf-provider-latest/internal/diff"
+	"github.com/xenitab/tf-provider-latest/internal/httpclient"
+	"github.com/xenitab/tf-provider-latest/internal/provider"
+	"github.com/xenitab/tf-provider-latest/internal/result"
 	"github.com/xenitab/tf-provider-latest/internal/update"
 )
 
+// Exit code used by check mode when there are outdated versions.
+const outdatedExitCode = 2
+
+var renderers = map[string]func([]*result.Result) (string, error){
+	"markdown": result.ToMarkdown,
+	"json":     result.ToJSON,
+	"sarif":    result.ToSARIF,
+	"junit":    result.ToJUnit,
+	"github":   result.ToGitHub,
+}
+
+type config struct {
+	path              string
+	providerSelector  *[]string
+	providerStrategy  string
+	providerPlatforms []string
+	helmSelector      *[]string
+	check             bool
+	diff              bool
+	outputFormat      string
+	templatePath      string
+	registryHost      string
+	httpRetries       int
+	httpTimeout       time.Duration
+	cacheDir          string
+	cacheTTL          time.Duration
+	refresh           bool
+	concurrency       int
+	caFile            string
+	clientCert        string
+	clientKey         string
+}
+
 func main() {
 	// Disable Terraform logs
 	log.SetOutput(io.Discard)
@@ -18,13 +58,39 @@ func main() {
 	// Parse flags
 	path := flag.String("path", "", "path where directory recursion should start")
 	providerSelector := flag.StringSlice("provider-selector", nil, "optional selector for providers to update")
+	providerStrategy := flag.String("provider-strategy", provider.StrategyLatest, "provider version strategy, one of latest, minor or patch")
+	providerPlatforms := flag.StringSlice("provider-platforms", nil, "optional os_arch platforms provider versions must be built for")
 	helmSelector := flag.StringSlice("helm-selector", nil, "optional selector for Helm charts to update")
+	check := flag.Bool("check", false, "check for outdated versions without writing any changes, exits with code 2 if any are found")
+	printDiff := flag.Bool("diff", false, "print a unified diff of the changes instead of the report without writing any changes")
+	outputFormat := flag.String("output", "markdown", "format of the report, one of markdown, json, sarif, junit or github")
+	templatePath := flag.String("template", "", "optional path to a Go template used to render the report instead of the output format")
+	registryHost := flag.String("registry-host", "", "registry host for provider sources without hostname, detected if not set")
+	httpRetries := flag.Int("http-retries", httpclient.DefaultRetries, "number of retries of failed registry and chart repository requests")
+	httpTimeout := flag.Duration("http-timeout", httpclient.DefaultTimeout, "timeout of a single registry or chart repository request")
+	cacheDir := flag.String("cache-dir", "", "directory where responses are cached, defaults to the user cache directory")
+	cacheTTL := flag.Duration("cache-ttl", httpclient.DefaultCacheTTL, "how long cached responses are used, set to 0 to disable the cache")
+	refresh := flag.Bool("refresh", false, "ignore cached responses and request them again")
+	concurrency := flag.Int("concurrency", update.DefaultConcurrency, "number of providers and Helm charts resolved at the same time")
+	caFile := flag.String("ca-file", "", "optional PEM CA bundle trusted in addition to the system roots for all requests")
+	clientCert := flag.String("client-cert", "", "optional PEM client certificate used for all requests, requires client-key")
+	clientKey := flag.String("client-key", "", "optional PEM client key used for all requests, requires client-cert")
 	flag.Parse()
 
 	if *path == "" {
 		fmt.Println("path flag must be set")
 		os.Exit(1)
 	}
+	if _, ok := renderers[*outputFormat]; !ok {
+		fmt.Println("output flag must be one of markdown, json, sarif, junit or github")
+		os.Exit(1)
+	}
+	switch *providerStrategy {
+	case provider.StrategyLatest, provider.StrategyMinor, provider.StrategyPatch:
+	default:
+		fmt.Println("provider-strategy flag must be one of latest, minor or patch")
+		os.Exit(1)
+	}
 	if !flag.Lookup("provider-selector").Changed {
 		providerSelector = nil
 	}
@@ -32,12 +98,129 @@ func main() {
 		helmSelector = nil
 	}
 
-	// Run update logic
-	fs := afero.NewOsFs()
-	output, err := update.Update(fs, *path, providerSelector, helmSelector)
+	cfg := config{
+		path:              *path,
+		providerSelector:  providerSelector,
+		providerStrategy:  *providerStrategy,
+		providerPlatforms: *providerPlatforms,
+		helmSelector:      helmSelector,
+		check:             *check,
+		diff:              *printDiff,
+		outputFormat:      *outputFormat,
+		templatePath:      *templatePath,
+		registryHost:      *registryHost,
+		httpRetries:       *httpRetries,
+		httpTimeout:       *httpTimeout,
+		cacheDir:          *cacheDir,
+		cacheTTL:          *cacheTTL,
+		refresh:           *refresh,
+		concurrency:       *concurrency,
+		caFile:            *caFile,
+		clientCert:        *clientCert,
+		clientKey:         *clientKey,
+	}
+	outdated, err := run(cfg)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	fmt.Println(output)
+	if cfg.check && outdated {
+		os.Exit(outdatedExitCode)
+	}
+}
+
+// run updates the versions and prints the output, returning true if any version is outdated.
+func run(cfg config) (bool, error) {
+	tmpl := ""
+	if cfg.templatePath != "" {
+		b, err := os.ReadFile(cfg.templatePath)
+		if err != nil {
+			return false, fmt.Errorf("unable to read template: %w", err)
+		}
+		tmpl = string(b)
+	}
+
+	// Write changes to an in memory layer when checking or diffing to keep the files on disk untouched
+	base := afero.NewOsFs()
+	layer := afero.NewMemMapFs()
+	var fs afero.Fs = base
+	if cfg.check || cfg.diff {
+		fs = afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), layer)
+	}
+
+	cliConfig, err := cliconfig.Load(base)
+	if err != nil {
+		return false, fmt.Errorf("unable to load terraform cli config: %w", err)
+	}
+
+	// Run update logic
+	opts := update.Options{
+		ProviderSelector:  cfg.providerSelector,
+		ProviderStrategy:  cfg.providerStrategy,
+		ProviderPlatforms: cfg.providerPlatforms,
+		HelmSelector:      cfg.helmSelector,
+		CLIConfig:         cliConfig,
+		RegistryHost:      cfg.registryHost,
+		HTTP:              httpOptions(base, cfg),
+		Concurrency:       cfg.concurrency,
+	}
+	results, err := update.Update(fs, cfg.path, opts)
+	if err != nil {
+		// Errors are part of the template data so that a report can be rendered for failed runs
+		if tmpl == "" {
+			return false, err
+		}
+		output, tmplErr := result.ToTemplate(tmpl, []*result.Result{}, []error{err})
+		if tmplErr != nil {
+			return false, tmplErr
+		}
+		fmt.Println(output)
+		return false, err
+	}
+
+	var output string
+	switch {
+	case cfg.diff:
+		output, err = diff.Diff(base, layer, cfg.path)
+	case tmpl != "":
+		output, err = result.ToTemplate(tmpl, results, nil)
+	default:
+		output, err = renderers[cfg.outputFormat](results)
+	}
+	if err != nil {
+		return false, err
+	}
+	if cfg.diff {
+		fmt.Print(output)
+	} else {
+		fmt.Println(output)
+	}
+
+	return result.HasUpdates(results), nil
+}
+
+// httpOptions returns the HTTP options with a cache on the base file system so that it is written in check and diff mode.
+func httpOptions(base afero.Fs, cfg config) httpclient.Options {
+	opts := httpclient.DefaultOptions()
+	opts.Retries = cfg.httpRetries
+	opts.Timeout = cfg.httpTimeout
+	opts.TLS = httpclient.TLSConfig{
+		CAFile:   cfg.caFile,
+		CertFile: cfg.clientCert,
+		KeyFile:  cfg.clientKey,
+	}
+	if cfg.cacheTTL <= 0 {
+		return opts
+	}
+	dir := cfg.cacheDir
+	if dir == "" {
+		var err error
+		dir, err = httpclient.DefaultCacheDir()
+		// run without a cache when there is no user cache directory
+		if err != nil {
+			return opts
+		}
+	}
+	opts.Cache = httpclient.NewCache(base, dir, cfg.cacheTTL, cfg.refresh)
+	return opts
 }